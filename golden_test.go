@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata/golden")
+
+// Directory of the files generated from the kmd files of testdata/kmd
+const goldenDir = "testdata/golden"
+
+// Reset the models loaded and the files generated by a previous test.
+func resetGenerator() {
+	DEFINITIONS = make(map[string]definition)
+	MODULES = make(map[string]*module)
+	MODULEOF = make(map[string]string)
+	PACKAGE = ""
+	CPXTYPEMODELS = make(map[string]ComplexType)
+	CLASSES = make(map[string]Class)
+	CPXEXTENDS = make(map[string]string)
+	EVENTS = make(map[string]Event)
+	GENERATED = make(map[string][]byte)
+}
+
+// Generate the package from testdata/kmd, without the runtime, and format
+// it like the generator does.
+func generateTestdata(t *testing.T) {
+	resetGenerator()
+	if err := generate([]string{"testdata/kmd/*.kmd.json"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := formatGenerated(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGolden compares the files generated from testdata/kmd with the ones
+// of testdata/golden, named after them with the suffix ".golden". Run
+// "go test -update" to rewrite them after changing the generator.
+func TestGolden(t *testing.T) {
+	generateTestdata(t)
+
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatal(err)
+		}
+		for path, src := range GENERATED {
+			dst := filepath.Join(goldenDir, path+".golden")
+			if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(dst, src, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	golden := make(map[string]bool)
+	err := filepath.Walk(goldenDir, func(f string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(goldenDir, f)
+		golden[strings.TrimSuffix(rel, ".golden")] = true
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, src := range GENERATED {
+		want, err := ioutil.ReadFile(filepath.Join(goldenDir, path+".golden"))
+		switch {
+		case err != nil:
+			t.Errorf("%s: %s", path, err)
+		case !bytes.Equal(src, want):
+			t.Errorf("%s differs from its golden file:\n%s", path, firstDiff(string(want), string(src)))
+		}
+		delete(golden, path)
+	}
+	for path := range golden {
+		t.Errorf("%s is not generated any more", path)
+	}
+}
+
+// Return the first line that differs between "want" and "got".
+func firstDiff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Sprintf("line %d:\n\twant %q\n\tgot  %q", i+1, wl, gl)
+		}
+	}
+	return ""
+}
+
+// TestTypeCheck type-checks the packages generated from testdata/kmd, with
+// the runtime, in one package and with -split. When golang.org/x/net, used
+// by the runtime, can't be imported, the rest of the code is still checked.
+func TestTypeCheck(t *testing.T) {
+	defer func(v bool) { *split = v }(*split)
+
+	for _, s := range []bool{false, true} {
+		*split = s
+		generateTestdata(t)
+		if err := copyRuntime(*baseDir); err != nil {
+			t.Fatal(err)
+		}
+		// the files of the output are replaced, as an older package
+		dir := t.TempDir()
+		if err := ioutil.WriteFile(filepath.Join(dir, "old.go"), []byte("package kurento\n\ntype MediaObject struct{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := typeCheckGenerated(dir); err != nil {
+			t.Errorf("split %v: %s", *split, err)
+		}
+	}
+}
+
+// TestRuntime runs "go vet" and the tests of the runtime, with the race
+// detector, on the package generated from testdata/kmd. It is skipped when
+// golang.org/x/net can't be imported.
+func TestRuntime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated package")
+	}
+	if out, err := exec.Command("go", "list", "golang.org/x/net/websocket").CombinedOutput(); err != nil {
+		t.Skipf("cannot build the runtime: %s", out)
+	}
+	gopath, err := exec.Command("go", "env", "GOPATH").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generateTestdata(t)
+	if err := copyRuntime(*baseDir); err != nil {
+		t.Fatal(err)
+	}
+	// the tests are copied as they are, for the default import path
+	err = filepath.Walk(*baseDir, func(f string, info os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(f, "_test.go") {
+			return err
+		}
+		rel, err := filepath.Rel(*baseDir, f)
+		if err != nil {
+			return err
+		}
+		GENERATED[rel], err = ioutil.ReadFile(f)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "src", filepath.FromSlash(*importPath))
+	if err := writeGenerated(dir); err != nil {
+		t.Fatal(err)
+	}
+	env := append(os.Environ(),
+		"GO111MODULE=off",
+		"GOPATH="+root+string(os.PathListSeparator)+strings.TrimSpace(string(gopath)))
+	// the kmd dump is tested against kurentotest, next to the package
+	dump := filepath.Join(filepath.Dir(dir), "kurento_kmd_dump")
+	if err := os.MkdirAll(dump, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join("kurento_kmd_dump", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dump, filepath.Base(f)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pkg := range []string{dir, dump} {
+		for _, args := range [][]string{
+			{"vet", "./..."},
+			{"test", "-race", "-count=1", "./..."},
+		} {
+			cmd := exec.Command("go", args...)
+			cmd.Dir = pkg
+			cmd.Env = env
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("go %s: %s\n%s", strings.Join(args, " "), err, out)
+			}
+		}
+	}
+}
+
+func TestSelectModules(t *testing.T) {
+	defer func(v bool) { *split = v }(*split)
+	globs := []string{"testdata/kmd/*.kmd.json"}
+
+	*split = false
+	resetGenerator()
+	err := generate(globs, []string{"elements"})
+	want := "module elements imports core; add it to -modules or use -split"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+
+	// the packages of the other modules are not generated again
+	*split = true
+	resetGenerator()
+	if err := generate(globs, []string{"elements"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := GENERATED["core.go"]; ok {
+		t.Error("core generated with elements")
+	}
+	if _, ok := GENERATED[filepath.Join("elements", "elements.go")]; !ok {
+		t.Error("elements not generated")
+	}
+	if runtimeSelected([]string{"elements"}) {
+		t.Error("runtime generated with elements")
+	}
+}
+
+// TestObsoleteGenerated checks that the Go files of the generated
+// directories that are not generated any more are stale, and removed.
+func TestObsoleteGenerated(t *testing.T) {
+	generateTestdata(t)
+	dir := t.TempDir()
+	if err := writeGenerated(dir); err != nil {
+		t.Fatal(err)
+	}
+	if stale := staleGenerated(dir); len(stale) != 0 {
+		t.Fatalf("stale after writing: %v", stale)
+	}
+
+	// the file of a module removed since, and the files of a directory
+	// that is not generated
+	for _, path := range []string{"filters.go", filepath.Join("kurentotest", "server.go")} {
+		dst := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(dst, []byte("package kurento\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"filters.go (obsolete)"}
+	if stale := staleGenerated(dir); !reflect.DeepEqual(stale, want) {
+		t.Errorf("got stale %v, want %v", stale, want)
+	}
+
+	if err := writeGenerated(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "filters.go")); !os.IsNotExist(err) {
+		t.Error("obsolete file not removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "kurentotest", "server.go")); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestWriteGeneratedFailure checks that the output is left as it was when
+// a file can't be replaced.
+func TestWriteGeneratedFailure(t *testing.T) {
+	defer func() { rename = os.Rename }()
+	resetGenerator()
+	dir := t.TempDir()
+	old := map[string]string{
+		"a.go": "package kurento // old a\n",
+		"c.go": "package kurento // obsolete c\n",
+	}
+	for path, src := range old {
+		if err := ioutil.WriteFile(filepath.Join(dir, path), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	GENERATED["a.go"] = []byte("package kurento // new a\n")
+	GENERATED["b.go"] = []byte("package kurento // new b\n")
+	rename = func(from, to string) error {
+		if filepath.Base(to) == "b.go" {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	if err := writeGenerated(dir); err == nil || err.Error() != "disk full" {
+		t.Fatalf("got %v, want the error of rename", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name()] = string(data)
+	}
+	if !reflect.DeepEqual(got, old) {
+		t.Errorf("got files %v, want %v", got, old)
+	}
+}
@@ -1,19 +1,73 @@
 package main
 
-import "kurento-client-go-generator/kurento"
-import "net/http"
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
 
-var pipeline = new(kurento.MediaPipeline)
-var master = new(kurento.WebRtcEndPoint)
-var server = kurento.NewConnection('ws://127.0.0.1:8888')
+	"kurento-client-go-generator/kurento"
+)
+
+var (
+	kms  = flag.String("kms", "ws://127.0.0.1:8888", "url of the media server")
+	addr = flag.String("addr", ":8080", "address to serve")
+)
+
+var server *kurento.Connection
 
 func main() {
-	http.HandleFunc("/static/", staticExecute)
-	http.ListenAndServe(":8080", nil)
+	flag.Parse()
+
+	var err error
+	server, err = kurento.Dial(context.Background(), *kms)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server.Close()
+
+	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	http.HandleFunc("/loopback", loopback)
+	log.Fatal(http.ListenAndServe(*addr, nil))
 }
 
+// loopback answers the SDP offer posted by a browser with a WebRTC
+// endpoint that sends back what it receives.
+func loopback(w http.ResponseWriter, r *http.Request) {
+	offer, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-func staticExecute(response http.Response, request *http.Request)  {
-	requestUrl := request.URL.String()
-	filepath := requestUrl[len("/static"):]
-}
\ No newline at end of file
+	ctx := r.Context()
+	pipeline, err := kurento.NewMediaPipelineCtx(ctx, server)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	answer, err := negotiate(ctx, pipeline, string(offer))
+	if err != nil {
+		pipeline.Release()
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Write([]byte(answer))
+}
+
+func negotiate(ctx context.Context, pipeline *kurento.MediaPipeline, offer string) (string, error) {
+	endpoint, err := kurento.NewWebRtcEndpointCtx(ctx, pipeline)
+	if err != nil {
+		return "", err
+	}
+	if err := endpoint.ConnectCtx(ctx, endpoint, nil, nil, nil); err != nil {
+		return "", err
+	}
+	answer, err := endpoint.ProcessOfferCtx(ctx, offer)
+	if err != nil {
+		return "", err
+	}
+	return answer, endpoint.GatherCandidatesCtx(ctx)
+}
@@ -1,69 +1,243 @@
 package kurento
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
-var debug = false
+// Set to 1 in debug mode, see Debug
+var debugMode int32
 
-// Debug activate debug information.
+// ErrReleased is returned by calls on a released object.
+var ErrReleased = errors.New("media object is released")
+
+// Debug activate debug information. It can be called while connections
+// are used.
 func Debug(state bool) {
-	debug = state
+	var v int32
+	if state {
+		v = 1
+	}
+	atomic.StoreInt32(&debugMode, v)
 }
 
-// IMadiaElement implements some basic methods as getConstructorParams or Create().
-type IMediaObject interface {
+// Return true in debug mode.
+func debug() bool {
+	return atomic.LoadInt32(&debugMode) == 1
+}
 
-	// Return the constructor parameters
-	getConstructorParams(IMediaObject, map[string]interface{}) map[string]interface{}
+// mediaObject is implemented by every generated class: its interface, and
+// the methods the runtime uses to create it. It is the contract between the
+// runtime and the generated code: the generator checks that its templates
+// define these methods.
+type mediaObject interface {
+	IMediaObject
 
-	// Each media object should be able to create another object
-	// Those options are sent to getConstructorParams
-	Create(IMediaObject, map[string]interface{})
+	// Return the constructor parameters
+	getConstructorParams(mediaObject, map[string]interface{}) map[string]interface{}
 
 	// Set ID of the element
 	setId(string)
 
-	//Implement Stringer
-	String() string
-
 	setParent(IMediaObject)
-	addChild(IMediaObject)
+	addChild(mediaObject)
 
 	setConnection(*Connection)
+
+	// Return the embedded MediaObject
+	object() *MediaObject
+}
+
+// ModuleClass is implemented by the classes of the modules generated in
+// their own package, instead of the unexported methods of mediaObject.
+type ModuleClass interface {
+	IMediaObject
+
+	// Return the constructor parameters, see Create
+	ConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{}
+
+	// Return the properties that can only be set by Create
+	FinalProperties() []string
+}
+
+// Create object "m" with given "options". The error sent by the server, if
+// any, is returned as an *Error.
+func (elem *MediaObject) Create(m mediaObject, options map[string]interface{}) error {
+	return elem.CreateCtx(context.Background(), m, options)
 }
 
-// Create object "m" with given "options"
-func (elem *MediaObject) Create(m IMediaObject, options map[string]interface{}) {
+// CreateCtx is like Create, with a context that can cancel the call.
+func (elem *MediaObject) CreateCtx(ctx context.Context, m mediaObject, options map[string]interface{}) error {
 	req := elem.getCreateRequest()
-	constparams := m.getConstructorParams(elem, options)
-	// TODO params["sessionId"]
-	req["params"] = map[string]interface{}{
+	constparams := make(map[string]interface{})
+	var final []string
+	if mc, ok := m.(ModuleClass); ok {
+		mergeOptions(constparams, mc.ConstructorParams(elem, options))
+		final = mc.FinalProperties()
+	} else {
+		mergeOptions(constparams, m.getConstructorParams(elem, options))
+		if f, ok := m.(interface {
+			finalProperties() []string
+		}); ok {
+			final = f.finalProperties()
+		}
+	}
+
+	// final properties that are not constructor params are sent apart
+	properties := make(map[string]interface{})
+	for _, name := range final {
+		if v, ok := constparams[name]; ok {
+			properties[name] = v
+			delete(constparams, name)
+		}
+	}
+
+	params := map[string]interface{}{
 		"type":              getMediaElementType(m),
 		"constructorParams": constparams,
 	}
-	if debug {
-		log.Printf("request to be sent: %+v\n", req)
+	if len(properties) > 0 {
+		params["properties"] = properties
+	}
+	req["params"] = params
+	if debug() {
+		elem.connection.log("request to be sent:", req)
 	}
 
 	m.setConnection(elem.connection)
 
-	res := <-elem.connection.Request(req)
+	res, err := elem.request(ctx, req)
+
+	if debug() {
+		elem.connection.log("Oncreate response: ", res)
+	}
+
+	if err != nil {
+		return err
+	}
+	var id string
+	if err := elem.decodeValue(res, &id); err != nil {
+		return err
+	}
+	if id == "" {
+		return fmt.Errorf("no id returned for the created %s", getMediaElementType(m))
+	}
+	elem.addChild(m)
+	//m.setParent(elem)
+	m.setId(id)
 
-	if debug {
-		log.Println("Oncreate response: ", res)
+	if debug() {
+		runtime.SetFinalizer(m, reportLeak)
 	}
+	return nil
+}
 
-	if res.Result["value"] != "" {
-		elem.addChild(m)
-		//m.setParent(elem)
-		m.setId(res.Result["value"])
+// Log objects collected without being released, that are still alive on
+// the server.
+func reportLeak(m mediaObject) {
+	if !m.object().isReleased() {
+		m.object().connection.log(getMediaElementType(m), m, "was never released")
 	}
 }
 
+// Release the object on the server. The objects it created are released
+// too. Calls on released objects return ErrReleased.
+func (elem *MediaObject) Release() error {
+	return elem.ReleaseCtx(context.Background())
+}
+
+// ReleaseCtx is like Release, with a context that can cancel the call.
+func (elem *MediaObject) ReleaseCtx(ctx context.Context) error {
+	req := elem.getCreateRequest()
+	req["method"] = "release"
+	req["params"] = map[string]interface{}{
+		"object": elem.Id,
+	}
+
+	if _, err := elem.request(ctx, req); err != nil {
+		return err
+	}
+	elem.markReleased()
+	return nil
+}
+
+// Invoke calls "operation" on the object with "params", and decodes the
+// value returned by the server into "ret", unless nil. The generated
+// classes call their operations with it. The error sent by the server, if
+// any, is returned as an *Error.
+func (elem *MediaObject) Invoke(ctx context.Context, operation string, params map[string]interface{}, ret interface{}) error {
+	req := elem.getInvokeRequest()
+	reqParams := map[string]interface{}{
+		"operation": operation,
+		"object":    elem.Id,
+	}
+	if params != nil {
+		reqParams["operationParams"] = params
+	}
+	req["params"] = reqParams
+
+	response, err := elem.request(ctx, req)
+	if err != nil || ret == nil {
+		return err
+	}
+	return elem.decodeValue(response, ret)
+}
+
+// Mark the object and the objects it created as released, and drop their
+// subscriptions. The server releases them with their parent.
+func (elem *MediaObject) markReleased() {
+	atomic.StoreInt32(&elem.released, 1)
+	if elem.connection != nil {
+		elem.connection.events.removeObject(elem.Id)
+	}
+	elem.mu.Lock()
+	childs := append([]IMediaObject(nil), elem.Childs...)
+	elem.mu.Unlock()
+	for _, child := range childs {
+		if o, ok := child.(mediaObject); ok {
+			o.object().markReleased()
+		}
+	}
+}
+
+// Return true once the object is released.
+func (elem *MediaObject) isReleased() bool {
+	return atomic.LoadInt32(&elem.released) == 1
+}
+
+// Implement object, see mediaObject
+func (elem *MediaObject) object() *MediaObject {
+	return elem
+}
+
+// ObjectOf returns the MediaObject embedded by "m", a class of this package
+// or of a generated module package. Constructors create objects from it.
+// Other implementations of IMediaObject, as fakes, return an error.
+func ObjectOf(m IMediaObject) (*MediaObject, error) {
+	o, ok := m.(interface {
+		object() *MediaObject
+	})
+	if !ok {
+		return nil, fmt.Errorf("%T is not an object of the media server", m)
+	}
+	return o.object(), nil
+}
+
+// request sends "req" about the object and waits for the response, see
+// Connection.Request.
+func (elem *MediaObject) request(ctx context.Context, req map[string]interface{}) (Response, error) {
+	if elem.isReleased() {
+		return Response{}, ErrReleased
+	}
+	return elem.connection.Request(ctx, req)
+}
+
 // Implement setConnection that allows element to handle connection
 func (elem *MediaObject) setConnection(c *Connection) {
 	elem.connection = c
@@ -76,7 +250,9 @@ func (elem *MediaObject) setParent(m IMediaObject) {
 }
 
 // Append child to the element
-func (elem *MediaObject) addChild(m IMediaObject) {
+func (elem *MediaObject) addChild(m mediaObject) {
+	elem.mu.Lock()
+	defer elem.mu.Unlock()
 	elem.Childs = append(elem.Childs, m)
 }
 
@@ -103,11 +279,188 @@ func (m *MediaObject) getInvokeRequest() map[string]interface{} {
 	return req
 }
 
+// MarshalJSON implements json.Marshaler. Remote objects are sent to the
+// server as their ID.
+func (m *MediaObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Id)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Remote objects are sent by the
+// server as their ID.
+func (m *MediaObject) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &m.Id)
+}
+
+// decodeValue unmarshals the "value" of a response into v. Remote objects
+// found in v are bound to the connection of elem.
+func (elem *MediaObject) decodeValue(response Response, v interface{}) error {
+	res := result{}
+	if err := json.Unmarshal(response.Result, &res); err != nil {
+		return err
+	}
+	if len(res.Value) == 0 {
+		return nil
+	}
+	return elem.unmarshal(res.Value, v)
+}
+
+// unmarshal decodes "data" into v, and binds the remote objects found in v
+// to the connection of elem.
+func (elem *MediaObject) unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	bindConnection(reflect.ValueOf(v), elem.connection)
+	return nil
+}
+
+// Set the connection on every remote object reachable from v.
+func bindConnection(v reflect.Value, c *Connection) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() || bindObject(v, c) {
+			return
+		}
+		bindConnection(v.Elem(), c)
+	case reflect.Struct:
+		if v.CanAddr() && bindObject(v.Addr(), c) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			bindConnection(v.Field(i), c)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			bindConnection(v.Index(i), c)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			bindConnection(v.MapIndex(key), c)
+		}
+	}
+}
+
+// Set the connection of v if it is a pointer to a remote object.
+func bindObject(v reflect.Value, c *Connection) bool {
+	if v.Kind() != reflect.Ptr || !v.CanInterface() {
+		return false
+	}
+	o, ok := v.Interface().(interface {
+		setConnection(*Connection)
+	})
+	if ok {
+		o.setConnection(c)
+	}
+	return ok
+}
+
 // String implements fmt.Stringer interface, return ID
 func (m *MediaObject) String() string {
 	return m.Id
 }
 
+// Subscription is returned by "OnXxx" methods. It holds the id given by
+// the server to an event subscription.
+type Subscription struct {
+	id       string
+	Type     string
+	object   *MediaObject
+	handler  func(json.RawMessage)
+	listener *listener
+}
+
+// Id returns the id given by the server to the subscription. It changes
+// when the subscription is sent again after a reconnection.
+func (s *Subscription) Id() string {
+	// subscriptions returned by fakes have no object
+	if s.object == nil {
+		return ""
+	}
+	return s.object.connection.events.idOf(s)
+}
+
+// Unsubscribe stops receiving events for this subscription.
+func (s *Subscription) Unsubscribe() error {
+	return s.UnsubscribeCtx(context.Background())
+}
+
+// UnsubscribeCtx is like Unsubscribe, with a context that can cancel the
+// call.
+func (s *Subscription) UnsubscribeCtx(ctx context.Context) error {
+	if s.object == nil {
+		return nil
+	}
+	req := s.object.getCreateRequest()
+	req["method"] = "unsubscribe"
+	req["params"] = map[string]interface{}{
+		"subscription": s.Id(),
+		"object":       s.object.Id,
+	}
+
+	if _, err := s.object.request(ctx, req); err != nil {
+		return err
+	}
+	s.object.connection.events.remove(s)
+	return nil
+}
+
+// Subscribe to "eventType" events raised by the object. "handler" is
+// called with the raw event data.
+func (elem *MediaObject) subscribe(ctx context.Context, eventType string, handler func(json.RawMessage)) (*Subscription, error) {
+	req := subscribeRequest(eventType, elem.Id)
+	s := &Subscription{
+		Type:    eventType,
+		object:  elem,
+		handler: handler,
+	}
+	events := elem.connection.events
+	events.add(s)
+
+	response, err := elem.request(ctx, req)
+	var id string
+	if err == nil {
+		err = elem.decodeValue(response, &id)
+	}
+	if err != nil {
+		events.remove(s)
+		return nil, err
+	}
+	events.bind(s, id)
+	return s, nil
+}
+
+// Subscribe calls "cb" with the "eventType" events raised by the object.
+// "cb" is a function with one parameter, as func(ErrorEvent), which the
+// events are decoded into. The generated "OnXxx" methods subscribe with it.
+func (elem *MediaObject) Subscribe(ctx context.Context, eventType string, cb interface{}) (*Subscription, error) {
+	f := reflect.ValueOf(cb)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 {
+		return nil, fmt.Errorf("cannot call %T with %s events", cb, eventType)
+	}
+	evType := f.Type().In(0)
+
+	return elem.subscribe(ctx, eventType, func(data json.RawMessage) {
+		ev := reflect.New(evType)
+		if err := elem.unmarshal(data, ev.Interface()); err != nil {
+			elem.connection.log("Cannot decode ", eventType, " event: ", err)
+			return
+		}
+		f.Call([]reflect.Value{ev.Elem()})
+	})
+}
+
+// Build a request subscribing to "eventType" events of the object "id"
+func subscribeRequest(eventType, id string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "subscribe",
+		"params": map[string]interface{}{
+			"type":   eventType,
+			"object": id,
+		},
+	}
+}
+
 // Return name of the object
 func getMediaElementType(i interface{}) string {
 	n := reflect.TypeOf(i).String()
@@ -121,27 +474,33 @@ func mergeOptions(a, b map[string]interface{}) {
 	}
 }
 
-func setIfNotEmpty(param map[string]interface{}, name string, t interface{}) {
+// Bool returns a pointer to "v", to set an optional param of a method.
+// Optional params are not sent to the server when nil.
+func Bool(v bool) *bool {
+	return &v
+}
 
-	switch v := t.(type) {
-	case string:
-		if v != "" {
-			param[name] = v
-		}
-	case int, float64:
-		if v != 0 {
-			param[name] = v
-		}
-	case bool:
-		if v {
-			param[name] = v
-		}
-	case IMediaObject, fmt.Stringer:
-		if v != nil {
-			val := fmt.Sprintf("%s", v)
-			if val != "" {
-				param[name] = val
-			}
-		}
-	}
+// Int returns a pointer to "v", to set an optional param of a method.
+func Int(v int) *int {
+	return &v
+}
+
+// Int64 returns a pointer to "v", to set an optional param of a method.
+func Int64(v int64) *int64 {
+	return &v
+}
+
+// Float32 returns a pointer to "v", to set an optional param of a method.
+func Float32(v float32) *float32 {
+	return &v
+}
+
+// Float64 returns a pointer to "v", to set an optional param of a method.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// String returns a pointer to "v", to set an optional param of a method.
+func String(v string) *string {
+	return &v
 }
@@ -0,0 +1,166 @@
+package kurento
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	c := newFakeServer(t).connect()
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+
+	// the server sends an event to each subscription it creates, with the
+	// id of the request
+	type event struct {
+		Id float64
+	}
+	first, second := make(chan event, 2), make(chan event, 2)
+	if _, err := elem.Subscribe(context.Background(), "Event", func(ev event) { first <- ev }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := elem.Subscribe(context.Background(), "Event", func(ev event) { second <- ev }); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []event
+	for _, events := range []chan event{first, second} {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not received")
+		}
+	}
+	if got[0].Id == got[1].Id {
+		t.Errorf("both subscriptions received the event %v", got[0])
+	}
+	select {
+	case ev := <-first:
+		t.Errorf("first subscription received %v twice", ev)
+	case ev := <-second:
+		t.Errorf("second subscription received %v twice", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := elem.Subscribe(context.Background(), "Event", func() {}); err == nil {
+		t.Error("subscribed a callback without event param")
+	}
+}
+
+func TestDecodeIStats(t *testing.T) {
+	tests := []struct {
+		data string
+		want IStats
+	}{
+		{`{"type": "element", "id": "a"}`, &ElementStats{Stats: Stats{Id: "a", Type: STATSTYPE_ELEMENT}}},
+		{`{"type": "endpoint", "audioE2ELatency": 2}`,
+			&EndpointStats{ElementStats: ElementStats{Stats: Stats{Type: STATSTYPE_ENDPOINT}}, AudioE2ELatency: 2}},
+		// "__type__" is used first
+		{`{"__type__": "PlayerStats", "type": "endpoint", "cacheSize": 3}`,
+			&PlayerStats{EndpointStats: EndpointStats{ElementStats: ElementStats{Stats: Stats{Type: STATSTYPE_ENDPOINT}}}, CacheSize: 3}},
+		{`{"type": "unknown"}`, &Stats{Type: "unknown"}},
+		{`{"id": "b"}`, &Stats{Id: "b"}},
+	}
+	for _, test := range tests {
+		got, err := DecodeIStats([]byte(test.data))
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("DecodeIStats(%s) = %#v, %v, want %#v", test.data, got, err, test.want)
+		}
+	}
+	if _, err := DecodeIStats([]byte(`{"type": 1}`)); err == nil {
+		t.Error("decoded a type that is not a string")
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	c := &Connection{}
+	elem := &MediaObject{Id: "object", connection: c}
+
+	var tags []Tag
+	response := Response{Result: json.RawMessage(`{"value": [{"key": "a", "value": "1"}, {"key": "b", "value": "2"}]}`)}
+	if err := elem.decodeValue(response, &tags); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Tag{{"a", "1"}, {"b", "2"}}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v, want %v", tags, want)
+	}
+
+	// remote objects are sent as their id, and use the connection
+	var pipelines []*MediaPipeline
+	response = Response{Result: json.RawMessage(`{"value": ["pipeline"]}`)}
+	if err := elem.decodeValue(response, &pipelines); err != nil {
+		t.Fatal(err)
+	}
+	if len(pipelines) != 1 || pipelines[0].Id != "pipeline" || pipelines[0].connection != c {
+		t.Errorf("got %v, want the pipeline bound to the connection", pipelines)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	c := newFakeServer(t).connect()
+	ctx := context.Background()
+	pipeline := &MediaPipeline{}
+	if err := c.CreateCtx(ctx, pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	child := &MediaPipeline{}
+	if err := pipeline.CreateCtx(ctx, child, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Release(); err != nil {
+		t.Fatal(err)
+	}
+	// the objects it created are released with it
+	for _, elem := range []*MediaPipeline{pipeline, child} {
+		if err := elem.Invoke(ctx, "echo", nil, nil); err != ErrReleased {
+			t.Errorf("%s: got %v, want ErrReleased", elem.Id, err)
+		}
+	}
+	if err := pipeline.Release(); err != ErrReleased {
+		t.Errorf("released twice: got %v, want ErrReleased", err)
+	}
+}
+
+func TestReportLeak(t *testing.T) {
+	Debug(true)
+	defer Debug(false)
+	out := &lockedBuffer{}
+	c := newFakeServer(t).connect(WithLogger(log.New(out, "", 0)))
+
+	released := &MediaPipeline{}
+	if err := c.CreateCtx(context.Background(), released, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := released.Release(); err != nil {
+		t.Fatal(err)
+	}
+	// the object is only referenced by this function
+	leaked := func() string {
+		elem := &MediaPipeline{}
+		if err := c.CreateCtx(context.Background(), elem, nil); err != nil {
+			t.Fatal(err)
+		}
+		return elem.Id
+	}()
+
+	want := "MediaPipeline " + leaked + " was never released"
+	for i := 0; i < 100 && !strings.Contains(out.String(), want); i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("%q not logged:\n%s", want, out)
+	}
+	if strings.Contains(out.String(), released.Id+" was never released") {
+		t.Errorf("released object %s reported", released.Id)
+	}
+	runtime.KeepAlive(released)
+}
@@ -0,0 +1,91 @@
+package kurento
+
+import (
+	"context"
+	"sync"
+)
+
+// Cache shares connections to media servers by url. The zero value is
+// ready to use.
+type Cache struct {
+	mu          sync.Mutex
+	connections map[string]*Connection
+	dialing     map[string]*dialCall
+}
+
+// Dial of a url in progress, waited by the other callers of Cache.Dial
+type dialCall struct {
+	done chan struct{}
+	c    *Connection
+	err  error
+}
+
+// Dial returns the connection of the cache to "url", or dials it. Options
+// are only used by new connections. Closed connections are dialed again.
+// Concurrent calls for the same url wait for the same dial, and calls for
+// other urls don't wait for it.
+func (cache *Cache) Dial(ctx context.Context, url string, opts ...Option) (*Connection, error) {
+	for {
+		cache.mu.Lock()
+		if c := cache.connections[url]; c != nil && !c.isClosed() {
+			cache.mu.Unlock()
+			return c, nil
+		}
+		call := cache.dialing[url]
+		if call == nil {
+			return cache.dial(ctx, url, opts)
+		}
+		cache.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err == nil {
+			return call.c, nil
+		}
+		// the dial failed, maybe because its context was canceled: this
+		// call dials again
+	}
+}
+
+// Dial "url", with the lock held, and add the connection to the cache.
+// The lock is released during the dial.
+func (cache *Cache) dial(ctx context.Context, url string, opts []Option) (*Connection, error) {
+	call := &dialCall{done: make(chan struct{})}
+	if cache.dialing == nil {
+		cache.dialing = make(map[string]*dialCall)
+	}
+	cache.dialing[url] = call
+	cache.mu.Unlock()
+
+	call.c, call.err = Dial(ctx, url, opts...)
+
+	cache.mu.Lock()
+	delete(cache.dialing, url)
+	if call.err == nil {
+		if cache.connections == nil {
+			cache.connections = make(map[string]*Connection)
+		}
+		cache.connections[url] = call.c
+	}
+	cache.mu.Unlock()
+	close(call.done)
+	return call.c, call.err
+}
+
+// Close every connection of the cache. Connections being dialed are added
+// to the cache once dialed.
+func (cache *Cache) Close() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	var ret error
+	for url, c := range cache.connections {
+		if err := c.Close(); err != nil && ret == nil {
+			ret = err
+		}
+		delete(cache.connections, url)
+	}
+	return ret
+}
@@ -0,0 +1,98 @@
+package kurento
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Error sent by the server in a response. Its type can be matched with the
+// ErrXxx values, using errors.Is.
+type Error struct {
+	Code    int64
+	Message string
+	Data    ErrorData
+}
+
+// Implements error built-in interface
+func (e *Error) Error() string {
+	if e.Data.Type == "" {
+		return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%d] %s (%s)", e.Code, e.Message, e.Data.Type)
+}
+
+// Is reports whether the server sent an error of type "target".
+func (e *Error) Is(target error) bool {
+	t, ok := target.(ErrorType)
+	return ok && t == e.Data.Type
+}
+
+// ErrorData holds the details of an Error.
+type ErrorData struct {
+	// Type of the error, empty if the server didn't send any
+	Type ErrorType
+
+	// Data as sent by the server
+	Raw json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Some errors have no type, and
+// only a string as data.
+func (d *ErrorData) UnmarshalJSON(data []byte) error {
+	d.Raw = append(json.RawMessage(nil), data...)
+	head := struct {
+		Type ErrorType
+	}{}
+	if json.Unmarshal(data, &head) == nil {
+		d.Type = head.Type
+	}
+	return nil
+}
+
+// ErrorType is the type of an error sent by the server.
+type ErrorType string
+
+// Implements error built-in interface
+func (t ErrorType) Error() string {
+	return string(t)
+}
+
+// Types of the errors sent by the server
+const (
+	ErrMarshall             ErrorType = "MARSHALL_ERROR"
+	ErrUnmarshall           ErrorType = "UNMARSHALL_ERROR"
+	ErrUnexpected           ErrorType = "UNEXPECTED_ERROR"
+	ErrConnect              ErrorType = "CONNECT_ERROR"
+	ErrUnsupportedMediaType ErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrNotImplemented       ErrorType = "NOT_IMPLEMENTED"
+	ErrInvalidSession       ErrorType = "INVALID_SESSION"
+	ErrNotEnoughResources   ErrorType = "NOT_ENOUGH_RESOURCES"
+
+	ErrMediaObjectTypeNotFound                ErrorType = "MEDIA_OBJECT_TYPE_NOT_FOUND"
+	ErrMediaObjectNotFound                    ErrorType = "MEDIA_OBJECT_NOT_FOUND"
+	ErrMediaObjectHasNotParent                ErrorType = "MEDIA_OBJECT_HAS_NOT_PARENT"
+	ErrMediaObjectConstructorNotFound         ErrorType = "MEDIA_OBJECT_CONSTRUCTOR_NOT_FOUND"
+	ErrMediaObjectMethodNotFound              ErrorType = "MEDIA_OBJECT_METHOD_NOT_FOUND"
+	ErrMediaObjectEventNotSupported           ErrorType = "MEDIA_OBJECT_EVENT_NOT_SUPPORTED"
+	ErrMediaObjectIllegalParam                ErrorType = "MEDIA_OBJECT_ILLEGAL_PARAM_ERROR"
+	ErrMediaObjectNotAvailable                ErrorType = "MEDIA_OBJECT_NOT_AVAILABLE"
+	ErrMediaObjectNotFoundTransactionNoCommit ErrorType = "MEDIA_OBJECT_NOT_FOUND_TRANSACTION_NO_COMMIT"
+	ErrMediaObjectTagKeyNotFound              ErrorType = "MEDIA_OBJECT_TAG_KEY_NOT_FOUND"
+	ErrMediaObjectOperationNotSupported       ErrorType = "MEDIA_OBJECT_OPERATION_NOT_SUPPORTED"
+
+	ErrSdpCreate                         ErrorType = "SDP_CREATE_ERROR"
+	ErrSdpParse                          ErrorType = "SDP_PARSE_ERROR"
+	ErrSdpEndPointNoLocalSdp             ErrorType = "SDP_END_POINT_NO_LOCAL_SDP_ERROR"
+	ErrSdpEndPointNoRemoteSdp            ErrorType = "SDP_END_POINT_NO_REMOTE_SDP_ERROR"
+	ErrSdpEndPointGenerateOffer          ErrorType = "SDP_END_POINT_GENERATE_OFFER_ERROR"
+	ErrSdpEndPointProcessOffer           ErrorType = "SDP_END_POINT_PROCESS_OFFER_ERROR"
+	ErrSdpEndPointProcessAnswer          ErrorType = "SDP_END_POINT_PROCESS_ANSWER_ERROR"
+	ErrSdpConfiguration                  ErrorType = "SDP_CONFIGURATION_ERROR"
+	ErrSdpEndPointAlreadyNegotiated      ErrorType = "SDP_END_POINT_ALREADY_NEGOTIATED"
+	ErrSdpEndPointNotOfferGenerated      ErrorType = "SDP_END_POINT_NOT_OFFER_GENERATED"
+	ErrSdpEndPointAnswerAlreadyProcessed ErrorType = "SDP_END_POINT_ANSWER_ALREADY_PROCCESED"
+	ErrSdpEndPointCannotCreateSession    ErrorType = "SDP_END_POINT_CANNOT_CREATE_SESSON"
+
+	ErrIceGatherCandidates ErrorType = "ICE_GATHER_CANDIDATES_ERROR"
+	ErrIceAddCandidate     ErrorType = "ICE_ADD_CANDIDATE_ERROR"
+)
@@ -0,0 +1,41 @@
+package kurento
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorData(t *testing.T) {
+	tests := []struct {
+		data string
+		typ  ErrorType
+	}{
+		{`{"type": "INVALID_SESSION"}`, ErrInvalidSession},
+		{`{"type": "SDP_PARSE_ERROR", "message": "bad offer"}`, ErrSdpParse},
+		{`"Object not found"`, ""},
+		{`{"code": 1}`, ""},
+		{`null`, ""},
+	}
+	for _, test := range tests {
+		e := &Error{}
+		body := `{"code": 40101, "message": "failed", "data": ` + test.data + `}`
+		if err := json.Unmarshal([]byte(body), e); err != nil {
+			t.Errorf("%s: %s", test.data, err)
+			continue
+		}
+		if e.Data.Type != test.typ || string(e.Data.Raw) != test.data {
+			t.Errorf("%s: got type %q and raw %s", test.data, e.Data.Type, e.Data.Raw)
+		}
+
+		// the error is matched by its type, also when wrapped
+		wrapped := fmt.Errorf("create: %w", e)
+		if test.typ != "" && !errors.Is(wrapped, test.typ) {
+			t.Errorf("%s: %v is not %s", test.data, wrapped, test.typ)
+		}
+		if errors.Is(wrapped, ErrUnexpected) {
+			t.Errorf("%s: %v is %s", test.data, wrapped, ErrUnexpected)
+		}
+	}
+}
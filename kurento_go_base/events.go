@@ -0,0 +1,235 @@
+package kurento
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Params of the "onEvent" notifications sent by the server.
+type eventParams struct {
+	Value struct {
+		Data         json.RawMessage
+		Object       string
+		Type         string
+		Subscription string
+	}
+	Subscription string
+}
+
+// eventRouter dispatches "onEvent" notifications to the subscriptions
+// registered on a connection. Subscriptions are indexed by their id and by
+// the id of the object that raises the events.
+type eventRouter struct {
+	mu       sync.Mutex
+	byId     map[string]*Subscription
+	byObject map[string][]*Subscription
+
+	// Logs like Connection.log
+	log func(v ...interface{})
+}
+
+func newEventRouter(log func(v ...interface{})) *eventRouter {
+	return &eventRouter{
+		byId:     make(map[string]*Subscription),
+		byObject: make(map[string][]*Subscription),
+		log:      log,
+	}
+}
+
+// Register a subscription and start delivering its events. It is
+// registered before the server gives its id, see bind, so that the events
+// sent right after the subscription are not lost.
+func (r *eventRouter) add(s *Subscription) {
+	s.listener = newListener(s.handler)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.id != "" {
+		r.byId[s.id] = s
+	}
+	r.byObject[s.object.Id] = append(r.byObject[s.object.Id], s)
+}
+
+// Set the id given by the server to a registered subscription. Events sent
+// with its previous id, if any, are dropped. An empty id makes it wait for
+// a new one, see add.
+func (r *eventRouter) bind(s *Subscription, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byId[s.id] == s {
+		delete(r.byId, s.id)
+	}
+	s.id = id
+	if id != "" {
+		r.byId[id] = s
+	}
+}
+
+// Return the id of a subscription.
+func (r *eventRouter) idOf(s *Subscription) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return s.id
+}
+
+// Return every registered subscription.
+func (r *eventRouter) all() []*Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []*Subscription
+	for _, subs := range r.byObject {
+		ret = append(ret, subs...)
+	}
+	return ret
+}
+
+// Unregister every subscription.
+func (r *eventRouter) removeAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subs := range r.byObject {
+		for _, s := range subs {
+			s.listener.stop()
+		}
+	}
+	r.byId = make(map[string]*Subscription)
+	r.byObject = make(map[string][]*Subscription)
+}
+
+// Unregister a subscription. Events already queued are dropped.
+func (r *eventRouter) remove(s *Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs := r.byObject[s.object.Id]
+	found := false
+	for i, sub := range subs {
+		if sub == s {
+			subs = append(subs[:i], subs[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+	if r.byId[s.id] == s {
+		delete(r.byId, s.id)
+	}
+	if len(subs) == 0 {
+		delete(r.byObject, s.object.Id)
+	} else {
+		r.byObject[s.object.Id] = subs
+	}
+	s.listener.stop()
+}
+
+// Unregister every subscription to events of the object "id".
+func (r *eventRouter) removeObject(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.byObject[id] {
+		delete(r.byId, s.id)
+		s.listener.stop()
+	}
+	delete(r.byObject, id)
+}
+
+// dispatch hands the "value" payload of a notification to the matching
+// subscriptions. It never blocks on listeners.
+func (r *eventRouter) dispatch(raw json.RawMessage) {
+	params := eventParams{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		if debug() {
+			r.log("Cannot decode event: ", err)
+		}
+		return
+	}
+
+	id := params.Subscription
+	if id == "" {
+		id = params.Value.Subscription
+	}
+
+	// Events of a subscription which id is not known yet are sent to the
+	// subscriptions waiting for their id.
+	r.mu.Lock()
+	var targets []*Subscription
+	if s, ok := r.byId[id]; ok {
+		targets = append(targets, s)
+	} else {
+		for _, s := range r.byObject[params.Value.Object] {
+			if s.Type == params.Value.Type && (id == "" || s.id == "") {
+				targets = append(targets, s)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if len(targets) == 0 && debug() {
+		r.log("Dropped event because there is no subscription ", params.Value.Type, params.Value.Object)
+	}
+	for _, s := range targets {
+		s.listener.push(params.Value.Data)
+	}
+}
+
+// listener delivers events to a handler in its own goroutine, in the order
+// they were received. Events are queued while the handler is busy, so a
+// slow handler only delays its own events.
+type listener struct {
+	mu      sync.Mutex
+	queue   []json.RawMessage
+	wake    chan struct{}
+	done    chan struct{}
+	handler func(json.RawMessage)
+}
+
+func newListener(handler func(json.RawMessage)) *listener {
+	l := &listener{
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		handler: handler,
+	}
+	go l.run()
+	return l
+}
+
+func (l *listener) push(data json.RawMessage) {
+	l.mu.Lock()
+	l.queue = append(l.queue, data)
+	l.mu.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (l *listener) stop() {
+	close(l.done)
+}
+
+func (l *listener) run() {
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.wake:
+		}
+
+		l.mu.Lock()
+		queue := l.queue
+		l.queue = nil
+		l.mu.Unlock()
+
+		for _, data := range queue {
+			select {
+			case <-l.done:
+				return
+			default:
+			}
+			l.handler(data)
+		}
+	}
+}
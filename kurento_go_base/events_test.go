@@ -0,0 +1,118 @@
+package kurento
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// Return an "onEvent" notification of "eventType" raised by "object" for
+// the subscription "id", with "data".
+func notification(id, object, eventType, data string) json.RawMessage {
+	return json.RawMessage(`{"value": {"subscription": "` + id + `", "object": "` + object +
+		`", "type": "` + eventType + `", "data": ` + data + `}}`)
+}
+
+// Register a subscription to "eventType" events of "object", with the id
+// "id", sending the data of its events to "received".
+func addSubscription(r *eventRouter, id, object, eventType string, received chan string) *Subscription {
+	s := &Subscription{
+		Type:    eventType,
+		object:  &MediaObject{Id: object},
+		handler: func(data json.RawMessage) { received <- string(data) },
+	}
+	r.add(s)
+	r.bind(s, id)
+	return s
+}
+
+// Return the events received before the timeout.
+func receivedEvents(received chan string) []string {
+	var ret []string
+	for {
+		select {
+		case data := <-received:
+			ret = append(ret, data)
+		case <-time.After(50 * time.Millisecond):
+			return ret
+		}
+	}
+}
+
+func TestEventRouter(t *testing.T) {
+	r := newEventRouter(func(...interface{}) {})
+	defer r.removeAll()
+	a, b, pending := make(chan string, 10), make(chan string, 10), make(chan string, 10)
+	addSubscription(r, "a", "object", "Event", a)
+	addSubscription(r, "b", "object", "Event", b)
+	// subscribed, waiting for the id given by the server
+	addSubscription(r, "", "object", "Event", pending)
+
+	r.dispatch(notification("a", "object", "Event", "1"))
+	r.dispatch(notification("b", "object", "Event", "2"))
+	r.dispatch(notification("c", "object", "Event", "3"))
+	r.dispatch(notification("d", "object", "Other", "4"))
+	r.dispatch(notification("e", "other", "Event", "5"))
+	r.dispatch(json.RawMessage(`"not an event"`))
+
+	tests := []struct {
+		name     string
+		received chan string
+		want     []string
+	}{
+		{"a", a, []string{"1"}},
+		{"b", b, []string{"2"}},
+		{"pending", pending, []string{"3"}},
+	}
+	for _, test := range tests {
+		got := receivedEvents(test.received)
+		if len(got) != len(test.want) || len(got) > 0 && got[0] != test.want[0] {
+			t.Errorf("subscription %s received %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	r.removeObject("object")
+	r.dispatch(notification("a", "object", "Event", "6"))
+	if got := receivedEvents(a); len(got) != 0 {
+		t.Errorf("removed subscription received %v", got)
+	}
+}
+
+func TestSlowListener(t *testing.T) {
+	r := newEventRouter(func(...interface{}) {})
+	defer r.removeAll()
+	slow, fast := make(chan string), make(chan string, 10)
+	addSubscription(r, "slow", "object", "Event", slow)
+	addSubscription(r, "fast", "object", "Event", fast)
+
+	// the slow handler blocks until its events are read, and doesn't delay
+	// the dispatch nor the other handlers
+	done := make(chan bool)
+	go func() {
+		for _, data := range []string{"1", "2", "3"} {
+			r.dispatch(notification("slow", "object", "Event", data))
+		}
+		r.dispatch(notification("fast", "object", "Event", "4"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatch blocked by a slow handler")
+	}
+	if got := receivedEvents(fast); len(got) != 1 {
+		t.Errorf("fast handler received %v, want 4", got)
+	}
+
+	// the events are delivered in order
+	for _, want := range []string{"1", "2", "3"} {
+		select {
+		case got := <-slow:
+			if got != want {
+				t.Errorf("got event %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s not delivered", want)
+		}
+	}
+}
@@ -1,102 +1,280 @@
 package kurento
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
+	"sync"
 
 	"golang.org/x/net/websocket"
 )
 
-// Error that can be filled in response
-type Error struct {
-	Code    int64
-	Message string
-	Data    string
-}
-
-// Implements error built-in interface
-func (e *Error) Error() string {
-	return fmt.Sprintf("[%d] %s %s", e.Code, e.Message, e.Data)
-}
-
 // Response represents server response
 type Response struct {
 	Jsonrpc string
 	Id      float64
-	Result  map[string]string // should change if result has no several form
+	Result  json.RawMessage // decoded by the caller, see decodeValue
 	Error   *Error
+
+	// Set on notifications sent by the server, as "onEvent"
+	Method string
+	Params json.RawMessage
 }
 
-type Connection struct {
-	clientId  float64
-	clients   map[float64]chan Response
-	host      string
-	ws        *websocket.Conn
+// Result of a request, as sent by the server
+type result struct {
+	Value     json.RawMessage
 	SessionId string
 }
 
-var connections = make(map[string]*Connection)
+// Connection to a media server. It is safe for concurrent use.
+type Connection struct {
+	events *eventRouter
+	config *websocket.Config
+	logger *log.Logger
+	ws     *websocket.Conn
+
+	// Closed by Close
+	done chan struct{}
+
+	// Guards the pending requests, by id, the session and the hooks
+	mu           sync.Mutex
+	clientId     float64
+	clients      map[float64]*waiter
+	sessionId    string
+	err          error
+	closed       bool
+	onDisconnect func(error)
+	onReconnect  func(bool)
+
+	// Serializes writes to ws, and guards it
+	writeMu sync.Mutex
+}
+
+// ErrClosed is returned by requests on a closed Connection.
+var ErrClosed = errors.New("connection closed")
 
-func NewConnection(host string) *Connection {
-	if connections[host] != nil {
-		return connections[host]
+// Dial connects to the media server at "url", as "ws://127.0.0.1:8888" or
+// "wss://kms.example.com/kurento". The connection is made again when it is
+// lost, until Close is called.
+func Dial(ctx context.Context, url string, opts ...Option) (*Connection, error) {
+	o := newOptions(opts)
+	config, err := o.websocketConfig(url)
+	if err != nil {
+		return nil, err
 	}
 
-	c := new(Connection)
-	connections[host] = c
-
-	c.clients = make(map[float64]chan Response)
-	var err error
-	c.ws, err = websocket.Dial(host+"/kurento", "", "http://127.0.0.1")
+	c := &Connection{
+		config:  config,
+		logger:  o.logger,
+		done:    make(chan struct{}),
+		clients: make(map[float64]*waiter),
+	}
+	c.events = newEventRouter(c.log)
+	c.ws, err = c.dial(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	go c.handleResponse(c.ws)
+
+	if err := c.connect(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if o.keepalive > 0 {
+		go c.keepalive(o.keepalive, o.missedPongs)
+	}
+	return c, nil
+}
+
+// Close the connection. Pending and next requests fail with ErrClosed.
+func (c *Connection) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	close(c.done)
+	c.closePending(ErrClosed)
+	c.events.removeAll()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.Close()
+}
+
+// Return true once the connection is closed.
+func (c *Connection) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// Log "v" to the logger of the connection, or to the standard logger in
+// debug mode.
+func (c *Connection) log(v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Println(v...)
+	} else if debug() {
+		log.Println(v...)
 	}
-	c.host = host
-	go c.handleResponse()
-	return c
 }
 
-func (c *Connection) Create(m IMediaObject, options map[string]interface{}) {
+// SessionId returns the id of the session given by the server.
+func (c *Connection) SessionId() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionId
+}
+
+func (c *Connection) Create(m mediaObject, options map[string]interface{}) error {
+	return c.CreateCtx(context.Background(), m, options)
+}
+
+// CreateCtx is like Create, with a context that can cancel the call.
+func (c *Connection) CreateCtx(ctx context.Context, m mediaObject, options map[string]interface{}) error {
 	elem := &MediaObject{}
 	elem.setConnection(c)
-	elem.Create(m, options)
+	return elem.CreateCtx(ctx, m, options)
 }
 
-func (c *Connection) handleResponse() {
-	for { // run forever
+// handleResponse reads messages from "ws" until it is closed, and hands
+// them to the waiting requests or to the event subscriptions. Then it
+// reconnects.
+func (c *Connection) handleResponse(ws *websocket.Conn) {
+	for {
 		r := Response{}
-		websocket.JSON.Receive(c.ws, &r)
-		if r.Result["sessionId"] != "" {
-			if debug {
-				log.Println("SESSIONID RETURNED")
+		if err := websocket.JSON.Receive(ws, &r); err != nil {
+			ws.Close()
+			if c.isClosed() {
+				return
 			}
-			c.SessionId = r.Result["sessionId"]
+			c.log("Cannot read from the server: ", err)
+			c.closePending(ErrDisconnected)
+			go c.reconnect(err)
+			return
+		}
+		// server-initiated notifications have no id
+		if r.Method == "onEvent" {
+			c.events.dispatch(r.Params)
+			continue
+		}
+		res := result{}
+		json.Unmarshal(r.Result, &res)
+
+		c.mu.Lock()
+		if res.SessionId != "" {
+			c.sessionId = res.SessionId
 		}
-		// if webscocket client exists, send response to the chanel
-		if c.clients[r.Id] != nil {
-			c.clients[r.Id] <- r
-			// chanel is read, we can delete it
-			delete(c.clients, r.Id)
-		} else if debug {
-			log.Println("Dropped message because there is no client ", r.Id)
-			log.Println(r)
+		wait := c.clients[r.Id]
+		delete(c.clients, r.Id)
+		c.mu.Unlock()
+
+		// the channel is buffered, the client may have stopped waiting
+		if wait != nil {
+			wait.response <- r
+		} else {
+			c.log("Dropped message because there is no client ", r.Id, r)
 		}
+	}
+}
 
+// A request waiting for its response
+type waiter struct {
+	// Buffered, closed when the request failed
+	response chan Response
+	// Set before response is closed
+	err error
+}
+
+// Fail every pending request, and the next ones, with "err". The next
+// requests are sent again once err is reset to nil, unless the connection
+// is closed.
+func (c *Connection) closePending(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		err = ErrClosed
+	}
+	c.err = err
+	// each request keeps its error, c.err is reset once reconnected
+	for id, wait := range c.clients {
+		wait.err = err
+		close(wait.response)
+		delete(c.clients, id)
 	}
 }
 
-func (c *Connection) Request(req map[string]interface{}) <-chan Response {
+// Request sends "req" and waits for its response, or for "ctx" to be done.
+// The error sent by the server, if any, is returned as an *Error.
+func (c *Connection) Request(ctx context.Context, req map[string]interface{}) (Response, error) {
+	// requests of a done context are not sent
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	wait := &waiter{response: make(chan Response, 1)}
+
+	c.mu.Lock()
+	if err := c.err; err != nil {
+		c.mu.Unlock()
+		return Response{}, err
+	}
 	c.clientId++
-	req["id"] = c.clientId
-	if c.SessionId != "" {
-		req["sesionId"] = c.SessionId
+	id := c.clientId
+	req["id"] = id
+	// the session is sent in the params of every request
+	if c.sessionId != "" {
+		params, ok := req["params"].(map[string]interface{})
+		if !ok {
+			params = make(map[string]interface{})
+			req["params"] = params
+		}
+		if _, ok := params["sessionId"]; !ok {
+			params["sessionId"] = c.sessionId
+		}
 	}
-	c.clients[c.clientId] = make(chan Response)
-	if debug {
+	c.clients[id] = wait
+	c.mu.Unlock()
+
+	if debug() {
 		j, _ := json.MarshalIndent(req, "", "    ")
-		log.Println("json", string(j))
+		c.log("json", string(j))
+	}
+	if err := c.send(req); err != nil {
+		c.forget(id)
+		return Response{}, err
+	}
+
+	select {
+	case response, ok := <-wait.response:
+		if !ok {
+			return Response{}, wait.err
+		}
+		if response.Error != nil {
+			return response, response.Error
+		}
+		return response, nil
+	case <-ctx.Done():
+		// the response will be dropped
+		c.forget(id)
+		return Response{}, ctx.Err()
 	}
-	websocket.JSON.Send(c.ws, req)
-	return c.clients[c.clientId]
+}
+
+// Send a message to the server. Messages are never interleaved.
+func (c *Connection) send(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return websocket.JSON.Send(c.ws, v)
+}
+
+// Stop waiting for the response of the request "id".
+func (c *Connection) forget(id float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.clients, id)
 }
@@ -0,0 +1,755 @@
+package kurento
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Number of concurrent calls made by the tests
+const concurrency = 500
+
+// fakeServer answers requests in random order, from concurrent goroutines.
+// Invocations of the "stall" operation are never answered, the "session"
+// operation returns the session of the request, and subscriptions are
+// followed by an "Event" event. Sessions are resumed unless "lost" is set,
+// in which case new sessions are created, and the next "refused" attempts
+// fail with an unexpected error. Pings are answered unless "deaf" is set.
+type fakeServer struct {
+	*httptest.Server
+	t       *testing.T
+	mu      sync.Mutex
+	ws      *websocket.Conn
+	lost    bool
+	refused int
+	deaf    bool
+}
+
+// Request received by fakeServer
+type fakeRequest struct {
+	Id     float64
+	Method string
+	Params struct {
+		Object          string
+		Type            string
+		Operation       string
+		OperationParams json.RawMessage
+		SessionId       string
+	}
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{t: t}
+	s.Server = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		s.mu.Lock()
+		s.ws = ws
+		s.mu.Unlock()
+		for {
+			var req fakeRequest
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				return
+			}
+			go s.answer(req)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) answer(req fakeRequest) {
+	time.Sleep(time.Duration(rand.Intn(1000)) * time.Microsecond)
+
+	id := req.Id
+	var value interface{}
+	switch req.Method {
+	case "create":
+		value = fmt.Sprintf("object-%v", id)
+	case "subscribe":
+		value = fmt.Sprintf("subscription-%v", id)
+	case "invoke":
+		switch req.Params.Operation {
+		case "stall":
+			return
+		case "session":
+			value = req.Params.SessionId
+		default:
+			value = req.Params.OperationParams
+		}
+	case "ping":
+		s.mu.Lock()
+		deaf := s.deaf
+		s.mu.Unlock()
+		if deaf {
+			return
+		}
+		value = "pong"
+	case "connect":
+		s.mu.Lock()
+		lost := s.lost
+		refused := req.Params.SessionId != "" && s.refused > 0
+		if refused {
+			s.refused--
+		}
+		s.mu.Unlock()
+		if refused {
+			s.send(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      id,
+				"error": map[string]interface{}{
+					"code":    40003,
+					"message": "Unexpected error",
+					"data":    map[string]interface{}{"type": "UNEXPECTED_ERROR"},
+				},
+			})
+			return
+		}
+		if lost && req.Params.SessionId != "" {
+			s.send(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      id,
+				"error": map[string]interface{}{
+					"code":    40007,
+					"message": "Invalid session",
+					"data":    map[string]interface{}{"type": "INVALID_SESSION"},
+				},
+			})
+			return
+		}
+	}
+	s.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"value":     value,
+			"sessionId": "session",
+		},
+	})
+
+	if req.Method == "subscribe" {
+		s.send(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "onEvent",
+			"params": map[string]interface{}{
+				"value": map[string]interface{}{
+					"subscription": value,
+					"object":       req.Params.Object,
+					"type":         "Event",
+					"data":         map[string]interface{}{"id": id},
+				},
+			},
+		})
+	}
+}
+
+func (s *fakeServer) send(v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	websocket.JSON.Send(s.ws, v)
+}
+
+// Close the connection of the client.
+func (s *fakeServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ws.Close()
+}
+
+// Return a connection to the server, closed at the end of the test.
+func (s *fakeServer) connect(opts ...Option) *Connection {
+	c, err := Dial(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http"), opts...)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.t.Cleanup(func() {
+		c.Close()
+	})
+	return c
+}
+
+// Return a request invoking "operation" on "object" with "params".
+func invokeRequest(object, operation string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "invoke",
+		"params": map[string]interface{}{
+			"object":          object,
+			"operation":       operation,
+			"operationParams": params,
+		},
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	c := newFakeServer(t).connect()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			elem := &MediaObject{Id: "object"}
+			elem.setConnection(c)
+
+			response, err := elem.request(context.Background(), invokeRequest(elem.Id, "echo", map[string]int{"n": i}))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got := map[string]int{}
+			if err := elem.decodeValue(response, &got); err != nil {
+				t.Error(err)
+			} else if got["n"] != i {
+				t.Errorf("got the response of request %d instead of %d", got["n"], i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if c.SessionId() != "session" {
+		t.Errorf("got session %q", c.SessionId())
+	}
+}
+
+func TestConcurrentCreate(t *testing.T) {
+	c := newFakeServer(t).connect()
+	parent := &MediaObject{Id: "parent"}
+	parent.setConnection(c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := parent.Create(&MediaObject{}, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids := make(map[string]bool)
+	for _, child := range parent.Childs {
+		ids[child.String()] = true
+	}
+	if len(ids) != concurrency {
+		t.Errorf("created %d distinct objects, want %d", len(ids), concurrency)
+	}
+}
+
+func TestConcurrentCancel(t *testing.T) {
+	c := newFakeServer(t).connect()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			operation := "echo"
+			if i%2 == 0 {
+				operation = "stall"
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := c.Request(ctx, invokeRequest("object", operation, nil))
+			if operation == "stall" && err != context.DeadlineExceeded {
+				t.Errorf("got %v for a stalled request", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.clients) != 0 {
+		t.Errorf("%d requests are still pending", len(c.clients))
+	}
+}
+
+// Return the number of requests waiting for their response.
+func pending(c *Connection) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.clients)
+}
+
+func TestCancel(t *testing.T) {
+	c := newFakeServer(t).connect()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Request(ctx, invokeRequest("object", "stall", nil))
+		done <- err
+	}()
+	for pending(c) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request not canceled")
+	}
+	if n := pending(c); n != 0 {
+		t.Errorf("%d requests are still pending", n)
+	}
+
+	// calls with a canceled context fail, and the connection is still used
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+	if err := elem.Invoke(ctx, "echo", nil, nil); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if err := elem.Invoke(context.Background(), "echo", nil, nil); err != nil {
+		t.Error(err)
+	}
+	if n := pending(c); n != 0 {
+		t.Errorf("%d requests are still pending", n)
+	}
+}
+
+// TestReconnectBeforeWakeUp checks that a request failed by a lost
+// connection returns the error, also when reconnected before it wakes up.
+func TestReconnectBeforeWakeUp(t *testing.T) {
+	c := newFakeServer(t).connect()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Request(context.Background(), invokeRequest("object", "stall", nil))
+		done <- err
+	}()
+	for pending(c) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// as the lost connection, then reconnect
+	c.closePending(ErrDisconnected)
+	c.mu.Lock()
+	c.err = nil
+	c.mu.Unlock()
+
+	select {
+	case err := <-done:
+		if err != ErrDisconnected {
+			t.Errorf("got %v, want ErrDisconnected", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending request not failed")
+	}
+}
+
+func TestConcurrentSubscriptions(t *testing.T) {
+	c := newFakeServer(t).connect()
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+
+	var wg sync.WaitGroup
+	received := make(chan string, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var s *Subscription
+			var mu sync.Mutex
+			mu.Lock()
+			defer mu.Unlock()
+			s, err := elem.subscribe(context.Background(), "Event", func(data json.RawMessage) {
+				mu.Lock()
+				defer mu.Unlock()
+				received <- s.Id()
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids := make(map[string]bool)
+	timeout := time.After(5 * time.Second)
+	for len(ids) < concurrency {
+		select {
+		case id := <-received:
+			ids[id] = true
+		case <-timeout:
+			t.Fatalf("received events of %d subscriptions, want %d", len(ids), concurrency)
+		}
+	}
+}
+
+// Subscribe to "Event" events of "elem", sent to "received".
+func subscribeEvents(t *testing.T, elem *MediaObject, received chan string) {
+	s, err := elem.subscribe(context.Background(), "Event", func(data json.RawMessage) {
+		received <- string(data)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if s.Id() == "" {
+		t.Fatal("subscription without id")
+	}
+}
+
+// Close the connection of the client while a request is pending, and return
+// the error of that request.
+func disconnect(t *testing.T, s *fakeServer, c *Connection) error {
+	done := make(chan error)
+	go func() {
+		_, err := c.Request(context.Background(), invokeRequest("object", "stall", nil))
+		done <- err
+	}()
+
+	// wait for the request to be pending
+	for {
+		c.mu.Lock()
+		n := len(c.clients)
+		c.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.drop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending request not failed")
+	}
+	return nil
+}
+
+func TestReconnect(t *testing.T) {
+	s := newFakeServer(t)
+	c := s.connect()
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+	received := make(chan string, 1)
+	subscribeEvents(t, elem, received)
+
+	disconnected := make(chan error, 1)
+	c.OnDisconnect(func(err error) {
+		disconnected <- err
+	})
+	reconnected := make(chan bool, 1)
+	c.OnReconnect(func(resumed bool) {
+		reconnected <- resumed
+	})
+
+	if err := disconnect(t, s, c); err != ErrDisconnected {
+		t.Errorf("got %v, want ErrDisconnected", err)
+	}
+	if err := <-disconnected; err == nil {
+		t.Error("OnDisconnect called without error")
+	}
+
+	select {
+	case resumed := <-reconnected:
+		if !resumed {
+			t.Error("session not resumed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected")
+	}
+
+	// the subscription was sent again, and is followed by an event
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not resumed")
+	}
+
+	if _, err := c.Request(context.Background(), invokeRequest("object", "echo", nil)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconnectLostSession(t *testing.T) {
+	s := newFakeServer(t)
+	c := s.connect()
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+	subscribeEvents(t, elem, make(chan string, 1))
+
+	reconnected := make(chan bool, 1)
+	c.OnReconnect(func(resumed bool) {
+		reconnected <- resumed
+	})
+
+	s.mu.Lock()
+	s.lost = true
+	s.mu.Unlock()
+	disconnect(t, s, c)
+
+	select {
+	case resumed := <-reconnected:
+		if resumed {
+			t.Error("lost session resumed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected")
+	}
+	if subs := c.events.all(); len(subs) != 0 {
+		t.Errorf("%d subscriptions kept from the lost session", len(subs))
+	}
+}
+
+// TestReconnectRefused checks that the session is kept when the server
+// fails to resume it for another reason than losing it, and that it is
+// resumed by the next attempt.
+func TestReconnectRefused(t *testing.T) {
+	s := newFakeServer(t)
+	c := s.connect()
+	elem := &MediaObject{Id: "object"}
+	elem.setConnection(c)
+	received := make(chan string, 1)
+	subscribeEvents(t, elem, received)
+
+	reconnected := make(chan bool, 1)
+	c.OnReconnect(func(resumed bool) {
+		reconnected <- resumed
+	})
+
+	s.mu.Lock()
+	s.refused = 1
+	s.mu.Unlock()
+	disconnect(t, s, c)
+
+	select {
+	case resumed := <-reconnected:
+		if !resumed {
+			t.Error("session dropped after an unexpected error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected")
+	}
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not resumed")
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := newFakeServer(t)
+	c := s.connect()
+
+	reconnected := make(chan bool, 1)
+	c.OnReconnect(func(resumed bool) {
+		reconnected <- resumed
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Request(context.Background(), invokeRequest("object", "stall", nil))
+		done <- err
+	}()
+	for {
+		c.mu.Lock()
+		n := len(c.clients)
+		c.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != ErrClosed {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+	if _, err := c.Request(context.Background(), invokeRequest("object", "echo", nil)); err != ErrClosed {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+
+	select {
+	case <-reconnected:
+		t.Error("reconnected after Close")
+	case <-time.After(2 * reconnectMinDelay):
+	}
+}
+
+func TestCache(t *testing.T) {
+	s := newFakeServer(t)
+	url := "ws" + strings.TrimPrefix(s.URL, "http")
+	cache := &Cache{}
+	defer cache.Close()
+
+	c1, err := cache.Dial(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := cache.Dial(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 {
+		t.Error("connection not shared")
+	}
+
+	c1.Close()
+	c3, err := cache.Dial(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3 == c1 {
+		t.Error("closed connection returned")
+	}
+}
+
+func TestCacheDialing(t *testing.T) {
+	// a server that never answers the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	stalled := "ws://" + ln.Addr().String()
+
+	cache := &Cache{}
+	defer cache.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.Dial(ctx, stalled)
+		done <- err
+	}()
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("not dialed")
+	}
+
+	// the other urls are dialed meanwhile
+	s := newFakeServer(t)
+	if _, err := cache.Dial(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same url waits for the dial in progress
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if _, err := cache.Dial(short, stalled); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want the deadline of the context", err)
+	}
+	select {
+	case <-accepted:
+		t.Error("dialed twice")
+	default:
+	}
+
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("dialed a server that doesn't answer")
+	}
+}
+
+func TestSession(t *testing.T) {
+	c := newFakeServer(t).connect()
+
+	// given by the response to "connect"
+	if c.SessionId() != "session" {
+		t.Fatalf("got session %q", c.SessionId())
+	}
+
+	response, err := c.Request(context.Background(), invokeRequest("object", "session", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := result{}
+	json.Unmarshal(response.Result, &res)
+	if string(res.Value) != `"session"` {
+		t.Errorf("request sent with session %s", res.Value)
+	}
+}
+
+func TestKeepalive(t *testing.T) {
+	s := newFakeServer(t)
+	c := s.connect(WithKeepalive(20*time.Millisecond, 2))
+
+	disconnected := make(chan error, 1)
+	c.OnDisconnect(func(err error) {
+		disconnected <- err
+	})
+
+	// pings are answered
+	select {
+	case <-disconnected:
+		t.Fatal("disconnected while the server answers pings")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.mu.Lock()
+	s.deaf = true
+	s.mu.Unlock()
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("missed pings not detected")
+	}
+}
+
+// Buffer that can be written by the connection while the test reads it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger(t *testing.T) {
+	Debug(true)
+	defer Debug(false)
+	out := &lockedBuffer{}
+	c := newFakeServer(t).connect(WithLogger(log.New(out, "", 0)))
+
+	if err := c.CreateCtx(context.Background(), &MediaPipeline{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.events.dispatch(json.RawMessage(`{"value": {"object": "object", "type": "Event", "subscription": "unknown"}}`))
+
+	for _, want := range []string{"request to be sent", "Dropped event"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%q not logged to the logger of the connection:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package kurentofake provides fakes of the classes of the kurento package,
+// to unit test code using their interfaces without a media server.
+//
+// Each fake records the calls of its methods, returned by Calls. Then they
+// call the function of the same name ending with "Func", if set by the
+// test, or return zero values:
+//
+//	endpoint := &kurentofake.WebRtcEndpoint{}
+//	endpoint.ProcessOfferFunc = func(ctx context.Context, offer string) (string, error) {
+//		return "answer", nil
+//	}
+//
+// Events are sent to the callbacks of the "OnXxx" methods with "EmitXxx".
+package kurentofake
+
+import (
+	"context"
+	"sync"
+)
+
+// Call of a method of a fake. Args don't include the context.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Records the calls of a fake, and keeps its event callbacks. It is
+// embedded by MediaObject, and so by every fake.
+type recorder struct {
+	mu        sync.Mutex
+	calls     []Call
+	callbacks map[string][]interface{}
+}
+
+// Calls returns the calls of the methods of the fake, in order. Methods
+// without context and their "Ctx" variant are recorded the same way.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// RecordCall records a call of "method". The fakes of the classes of other
+// packages record their calls with it.
+func (r *recorder) RecordCall(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{method, args})
+}
+
+// AddCallback keeps "cb", called by the "Emit" method of "eventType".
+func (r *recorder) AddCallback(eventType string, cb interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.callbacks == nil {
+		r.callbacks = make(map[string][]interface{})
+	}
+	r.callbacks[eventType] = append(r.callbacks[eventType], cb)
+}
+
+// Callbacks returns the callbacks of "eventType".
+func (r *recorder) Callbacks(eventType string) []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]interface{}(nil), r.callbacks[eventType]...)
+}
+
+// String implements fmt.Stringer, return Id
+func (f *MediaObject) String() string {
+	return f.Id
+}
+
+// Release records the call, then calls ReleaseFunc if set.
+func (f *MediaObject) Release() error {
+	return f.ReleaseCtx(context.Background())
+}
+
+// ReleaseCtx is like Release, with a context.
+func (f *MediaObject) ReleaseCtx(ctx context.Context) error {
+	f.RecordCall("Release")
+	if f.ReleaseFunc != nil {
+		return f.ReleaseFunc(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package kurentofake_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"kurento-client-go-generator/kurento"
+	"kurento-client-go-generator/kurento/kurentofake"
+)
+
+// Negotiate like an application would, with any WebRTC endpoint.
+func negotiate(endpoint kurento.IWebRtcEndpoint, offer string) (string, error) {
+	if err := endpoint.ConnectCtx(context.Background(), endpoint, nil, nil, nil); err != nil {
+		return "", err
+	}
+	answer, err := endpoint.ProcessOffer(offer)
+	if err != nil {
+		return "", err
+	}
+	return answer, endpoint.GatherCandidates()
+}
+
+func TestFake(t *testing.T) {
+	endpoint := &kurentofake.WebRtcEndpoint{}
+	endpoint.Id = "endpoint"
+	endpoint.ProcessOfferFunc = func(ctx context.Context, offer string) (string, error) {
+		return "answer to " + offer, nil
+	}
+
+	answer, err := negotiate(endpoint, "offer")
+	if err != nil || answer != "answer to offer" {
+		t.Errorf("got %q, %v, want the answer of ProcessOfferFunc", answer, err)
+	}
+
+	want := []kurentofake.Call{
+		{"Connect", []interface{}{endpoint, (*kurento.MediaType)(nil), (*string)(nil), (*string)(nil)}},
+		{"ProcessOffer", []interface{}{"offer"}},
+		{"GatherCandidates", nil},
+	}
+	if calls := endpoint.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %#v, want %#v", calls, want)
+	}
+}
+
+func TestFakeError(t *testing.T) {
+	endpoint := &kurentofake.WebRtcEndpoint{}
+	endpoint.GatherCandidatesFunc = func(ctx context.Context) error {
+		return kurento.ErrIceGatherCandidates
+	}
+
+	if _, err := negotiate(endpoint, "offer"); !errors.Is(err, kurento.ErrIceGatherCandidates) {
+		t.Errorf("got %v, want the error of GatherCandidatesFunc", err)
+	}
+}
+
+func TestFakeEmit(t *testing.T) {
+	endpoint := &kurentofake.WebRtcEndpoint{}
+
+	var got []kurento.IceCandidate
+	sub, err := endpoint.OnIceCandidateFound(func(ev kurento.IceCandidateFoundEvent) {
+		got = append(got, ev.Candidate)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint.EmitIceCandidateFound(kurento.IceCandidateFoundEvent{
+		Candidate: kurento.IceCandidate{Candidate: "candidate:1"},
+	})
+	if len(got) != 1 || got[0].Candidate != "candidate:1" {
+		t.Errorf("got candidates %v, want candidate:1", got)
+	}
+
+	// subscriptions of fakes do nothing
+	if err := sub.Unsubscribe(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFakeParent(t *testing.T) {
+	// fakes are not created on the media server, nor create objects
+	if _, err := kurento.NewHubPort(&kurentofake.Composite{}); err == nil {
+		t.Error("created a port from a fake")
+	}
+}
@@ -0,0 +1,450 @@
+// Package kurentotest provides a fake media server, to test code using the
+// kurento package without a Kurento Media Server.
+//
+// The server keeps the objects created by its clients in memory. Invoking
+// an operation calls the handler set for it with Handle. Operations without
+// a handler read and write the properties of the object ("getXxx" and
+// "setXxx"), or return nothing. Events are sent to subscribed clients with
+// Emit.
+package kurentotest
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// Handler handles the invocation of an operation on an object, given as a
+// copy. The value it returns is sent to the client, unless it returns an
+// error. Errors that are not an *Error are sent as an UNEXPECTED_ERROR.
+type Handler func(obj *Object, params map[string]interface{}) (interface{}, error)
+
+// Error sent to the client in a response.
+type Error struct {
+	Code    int
+	Message string
+	Type    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[%d] %s (%s)", e.Code, e.Message, e.Type)
+}
+
+// ServerManagerId is the id of the ServerManager of the server. It is not
+// created by clients, and Objects doesn't return it. Its "info" property,
+// a ServerInfo, lists no module until set with SetProperty.
+const ServerManagerId = "manager_ServerManager"
+
+// Errors sent by the server
+var (
+	errObjectNotFound       = &Error{40101, "Object not found", "MEDIA_OBJECT_NOT_FOUND"}
+	errInvalidSession       = &Error{40007, "Invalid session", "INVALID_SESSION"}
+	errMethodNotFound       = &Error{-32601, "Method not found", ""}
+	errSubscriptionNotFound = &Error{40106, "Subscription not found", "MEDIA_OBJECT_EVENT_NOT_SUPPORTED"}
+)
+
+// Object created on the server by a client.
+type Object struct {
+	Id   string
+	Type string
+
+	// Constructor params, properties and id of the object that created it
+	Params     map[string]interface{}
+	Properties map[string]interface{}
+	Parent     string
+
+	// Operations invoked on the object, in order
+	Calls []Call
+}
+
+// Call of an operation on an object.
+type Call struct {
+	Operation string
+	Params    map[string]interface{}
+}
+
+// Subscription of a client to events of an object
+type subscription struct {
+	id     string
+	object string
+	typ    string
+	client *client
+}
+
+// Connection of a client
+type client struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+func (c *client) send(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return websocket.JSON.Send(c.ws, v)
+}
+
+// Server is a fake media server, listening on a local port.
+type Server struct {
+	// URL of the server, to give to kurento.Dial
+	URL string
+
+	http *httptest.Server
+
+	mu            sync.Mutex
+	lastId        int
+	objects       map[string]*Object
+	handlers      map[string]Handler
+	subscriptions map[string]*subscription
+	sessions      map[string]bool
+	clients       map[*client]bool
+}
+
+// NewServer starts a Server. Call Close to stop it.
+func NewServer() *Server {
+	s := &Server{
+		objects:       make(map[string]*Object),
+		handlers:      make(map[string]Handler),
+		subscriptions: make(map[string]*subscription),
+		sessions:      make(map[string]bool),
+		clients:       make(map[*client]bool),
+	}
+	s.objects[ServerManagerId] = &Object{
+		Id:         ServerManagerId,
+		Type:       "ServerManager",
+		Properties: map[string]interface{}{"info": map[string]interface{}{"modules": []interface{}{}}},
+	}
+	s.http = httptest.NewServer(websocket.Handler(s.serve))
+	s.URL = "ws" + strings.TrimPrefix(s.http.URL, "http")
+	return s
+}
+
+// Close the connections of the clients, and stop the server.
+func (s *Server) Close() {
+	s.DropConnections()
+	s.http.Close()
+}
+
+// DropConnections closes the connections of the clients, as if the network
+// failed. Their sessions are kept.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.ws.Close()
+		delete(s.clients, c)
+	}
+}
+
+// Handle sets the handler of "operation" on objects of the type "typ", as
+// "WebRtcEndpoint". An empty type sets it for all types.
+func (s *Server) Handle(typ, operation string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[typ+"."+operation] = h
+}
+
+// Object returns a copy of the object "id", if it is not released.
+func (s *Server) Object(id string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[id]
+	if !ok {
+		return Object{}, false
+	}
+	return obj.copy(), true
+}
+
+// SetProperty sets the property "name" of the object "id", as returned by
+// its getter. It returns false if the object is released.
+func (s *Server) SetProperty(id, name string, value interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[id]
+	if ok {
+		obj.Properties[name] = value
+	}
+	return ok
+}
+
+// Objects returns a copy of the objects of the type "typ" created by the
+// clients that are not released, or of all of them if "typ" is empty.
+func (s *Server) Objects(typ string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []Object
+	for _, obj := range s.objects {
+		if obj.Id == ServerManagerId {
+			continue
+		}
+		if typ == "" || obj.Type == typ {
+			ret = append(ret, obj.copy())
+		}
+	}
+	return ret
+}
+
+// Emit sends the event "eventType", with "data", to the clients subscribed
+// to it on the object "id". It returns the number of subscriptions it was
+// sent to.
+func (s *Server) Emit(id, eventType string, data interface{}) int {
+	s.mu.Lock()
+	var subs []*subscription
+	for _, sub := range s.subscriptions {
+		if sub.object == id && sub.typ == eventType {
+			subs = append(subs, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.client.send(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "onEvent",
+			"params": map[string]interface{}{
+				"value": map[string]interface{}{
+					"data":         data,
+					"object":       id,
+					"type":         eventType,
+					"subscription": sub.id,
+				},
+			},
+		})
+	}
+	return len(subs)
+}
+
+// Request sent by a client
+type request struct {
+	Id     interface{}
+	Method string
+	Params map[string]interface{}
+}
+
+// Serve a client until it disconnects.
+func (s *Server) serve(ws *websocket.Conn) {
+	c := &client{ws: ws}
+	s.mu.Lock()
+	s.clients[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		req := request{}
+		if err := websocket.JSON.Receive(ws, &req); err != nil {
+			return
+		}
+		// handlers may block, as the media server does
+		go s.answer(c, req)
+	}
+}
+
+// Send the response to "req".
+func (s *Server) answer(c *client, req request) {
+	sessionId, _ := req.Params["sessionId"].(string)
+	value, err := s.handle(c, req, &sessionId)
+
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.Id,
+	}
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			e = &Error{40003, err.Error(), "UNEXPECTED_ERROR"}
+		}
+		response["error"] = map[string]interface{}{
+			"code":    e.Code,
+			"message": e.Message,
+			"data":    map[string]interface{}{"type": e.Type},
+		}
+	} else {
+		response["result"] = map[string]interface{}{
+			"value":     value,
+			"sessionId": sessionId,
+		}
+	}
+	c.send(response)
+}
+
+// Handle "req" from the client "c", in the session "sessionId". The session
+// is created if empty.
+func (s *Server) handle(c *client, req request, sessionId *string) (interface{}, error) {
+	s.mu.Lock()
+	if *sessionId == "" {
+		s.lastId++
+		*sessionId = fmt.Sprintf("session-%d", s.lastId)
+		s.sessions[*sessionId] = true
+	} else if !s.sessions[*sessionId] {
+		s.mu.Unlock()
+		return nil, errInvalidSession
+	}
+	s.mu.Unlock()
+
+	str := func(name string) string {
+		v, _ := req.Params[name].(string)
+		return v
+	}
+
+	switch req.Method {
+	case "connect":
+		return nil, nil
+	case "ping":
+		return "pong", nil
+	case "create":
+		params, _ := req.Params["constructorParams"].(map[string]interface{})
+		props, _ := req.Params["properties"].(map[string]interface{})
+		return s.create(str("type"), params, props), nil
+	case "invoke":
+		params, _ := req.Params["operationParams"].(map[string]interface{})
+		return s.invoke(str("object"), str("operation"), params)
+	case "release":
+		return nil, s.release(str("object"))
+	case "subscribe":
+		return s.subscribe(c, str("object"), str("type"))
+	case "unsubscribe":
+		return nil, s.unsubscribe(str("subscription"))
+	}
+	return nil, errMethodNotFound
+}
+
+// Create an object of type "typ". Its parent is the first object found in
+// its params.
+func (s *Server) create(typ string, params, props map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := &Object{
+		Type:       typ,
+		Params:     params,
+		Properties: make(map[string]interface{}),
+	}
+	for name, v := range props {
+		obj.Properties[name] = v
+	}
+	for _, v := range params {
+		if id, ok := v.(string); ok && s.objects[id] != nil {
+			obj.Parent = id
+			break
+		}
+	}
+
+	s.lastId++
+	obj.Id = fmt.Sprintf("%d_kurento.%s", s.lastId, typ)
+	if obj.Parent != "" {
+		obj.Id = obj.Parent + "/" + obj.Id
+	}
+	s.objects[obj.Id] = obj
+	return obj.Id
+}
+
+// Invoke "operation" on the object "id".
+func (s *Server) invoke(id, operation string, params map[string]interface{}) (interface{}, error) {
+	s.mu.Lock()
+	obj, ok := s.objects[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, errObjectNotFound
+	}
+	obj.Calls = append(obj.Calls, Call{operation, params})
+	h := s.handlers[obj.Type+"."+operation]
+	if h == nil {
+		h = s.handlers["."+operation]
+	}
+	if h != nil {
+		// handlers run without the lock, on a copy
+		cp := obj.copy()
+		s.mu.Unlock()
+		return h(&cp, params)
+	}
+	defer s.mu.Unlock()
+
+	// properties
+	if len(operation) > 3 {
+		name := strings.ToLower(operation[3:4]) + operation[4:]
+		switch operation[:3] {
+		case "get":
+			return obj.Properties[name], nil
+		case "set":
+			obj.Properties[name] = params[name]
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// Release the object "id", and the objects it created.
+func (s *Server) release(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects[id] == nil {
+		return errObjectNotFound
+	}
+	for oid := range s.objects {
+		if oid == id || strings.HasPrefix(oid, id+"/") {
+			delete(s.objects, oid)
+		}
+	}
+	for sid, sub := range s.subscriptions {
+		if s.objects[sub.object] == nil {
+			delete(s.subscriptions, sid)
+		}
+	}
+	return nil
+}
+
+// Subscribe the client "c" to "eventType" events of the object "id".
+func (s *Server) subscribe(c *client, id, eventType string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects[id] == nil {
+		return "", errObjectNotFound
+	}
+	s.lastId++
+	sub := &subscription{
+		id:     fmt.Sprintf("subscription-%d", s.lastId),
+		object: id,
+		typ:    eventType,
+		client: c,
+	}
+	s.subscriptions[sub.id] = sub
+	return sub.id, nil
+}
+
+// Remove the subscription "id".
+func (s *Server) unsubscribe(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscriptions[id] == nil {
+		return errSubscriptionNotFound
+	}
+	delete(s.subscriptions, id)
+	return nil
+}
+
+// Return a copy of the object, that can be used without the lock.
+func (obj *Object) copy() Object {
+	ret := *obj
+	ret.Params = copyMap(obj.Params)
+	ret.Properties = copyMap(obj.Properties)
+	ret.Calls = append([]Call(nil), obj.Calls...)
+	return ret
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,368 @@
+package kurentotest_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kurento-client-go-generator/kurento"
+	"kurento-client-go-generator/kurento/kurentotest"
+)
+
+// Dial the server, and close both at the end of the test.
+func dial(t *testing.T) (*kurentotest.Server, *kurento.Connection) {
+	s := kurentotest.NewServer()
+	t.Cleanup(s.Close)
+
+	c, err := kurento.Dial(context.Background(), s.URL, kurento.WithKeepalive(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return s, c
+}
+
+func TestCreate(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, ok := s.Object(endpoint.Id)
+	if !ok {
+		t.Fatalf("endpoint %s not found", endpoint.Id)
+	}
+	if obj.Type != "WebRtcEndpoint" || obj.Parent != pipeline.Id {
+		t.Errorf("got %s created by %q, want WebRtcEndpoint created by %q", obj.Type, obj.Parent, pipeline.Id)
+	}
+	if n := len(s.Objects("")); n != 2 {
+		t.Errorf("got %d objects, want 2", n)
+	}
+}
+
+func TestCreateFinalProperties(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// label is a final property and a constructor param, maxFaces is only
+	// a final property
+	filter, err := kurento.NewFaceOverlayFilter(pipeline,
+		kurento.FaceOverlayFilterLabel("mine"),
+		kurento.FaceOverlayFilterMaxFaces(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _ := s.Object(filter.Id)
+	params := map[string]interface{}{"mediaPipeline": pipeline.Id, "label": "mine"}
+	props := map[string]interface{}{"maxFaces": 2.0}
+	if !reflect.DeepEqual(obj.Params, params) || !reflect.DeepEqual(obj.Properties, props) {
+		t.Errorf("got constructorParams %v and properties %v, want %v and %v", obj.Params, obj.Properties, params, props)
+	}
+}
+
+func TestCreateOptionalFields(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// unset optional fields are not sent, zero values are
+	suite := kurento.CRYPTOSUITE_AES_128_CM_HMAC_SHA1_80
+	crypto := kurento.SDES{Key: kurento.String(""), Crypto: &suite}
+	endpoint, err := kurento.NewRtpEndpoint(pipeline, kurento.RtpEndpointCrypto(crypto))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _ := s.Object(endpoint.Id)
+	want := map[string]interface{}{"key": "", "crypto": "AES_128_CM_HMAC_SHA1_80"}
+	if !reflect.DeepEqual(obj.Params["crypto"], want) {
+		t.Errorf("got crypto %v, want %v", obj.Params["crypto"], want)
+	}
+}
+
+func TestCreateFromInterface(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, err := kurento.NewComposite(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a Composite is given as an IHub
+	port, err := kurento.NewHubPort(composite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _ := s.Object(port.Id)
+	if obj.Parent != composite.Id || obj.Params["hub"] != composite.Id {
+		t.Errorf("got port created by %q with params %v, want created by %q", obj.Parent, obj.Params, composite.Id)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	s, c := dial(t)
+	s.Handle("WebRtcEndpoint", "processOffer", func(obj *kurentotest.Object, params map[string]interface{}) (interface{}, error) {
+		return "answer to " + params["offer"].(string), nil
+	})
+	s.Handle("", "gatherCandidates", func(obj *kurentotest.Object, params map[string]interface{}) (interface{}, error) {
+		return nil, &kurentotest.Error{Code: 40208, Message: "Cannot gather", Type: "ICE_GATHER_CANDIDATES_ERROR"}
+	})
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answer, err := endpoint.ProcessOffer("offer")
+	if err != nil || answer != "answer to offer" {
+		t.Errorf("got %q, %v, want the scripted answer", answer, err)
+	}
+	err = endpoint.GatherCandidates()
+	if !errors.Is(err, kurento.ErrIceGatherCandidates) {
+		t.Errorf("got %v, want the scripted error", err)
+	}
+
+	obj, _ := s.Object(endpoint.Id)
+	if len(obj.Calls) != 2 || obj.Calls[0].Operation != "processOffer" {
+		t.Errorf("got calls %v, want processOffer and gatherCandidates", obj.Calls)
+	}
+}
+
+func TestInvokeParams(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidate := kurento.IceCandidate{Candidate: "candidate:1 1 UDP 1 10.0.0.1 9 typ host", SdpMid: "0"}
+	if err := endpoint.AddIceCandidate(candidate); err != nil {
+		t.Fatal(err)
+	}
+	// zero values are sent, nil optional params are not
+	if err := endpoint.CreateDataChannel(nil, kurento.Bool(false), kurento.Int(0), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _ := s.Object(endpoint.Id)
+	want := []kurentotest.Call{
+		{Operation: "addIceCandidate", Params: map[string]interface{}{
+			"candidate": map[string]interface{}{
+				"candidate":     candidate.Candidate,
+				"sdpMid":        "0",
+				"sdpMLineIndex": 0.0,
+			},
+		}},
+		{Operation: "createDataChannel", Params: map[string]interface{}{
+			"ordered":           false,
+			"maxPacketLifeTime": 0.0,
+		}},
+	}
+	if !reflect.DeepEqual(obj.Calls, want) {
+		t.Errorf("got calls %v, want %v", obj.Calls, want)
+	}
+}
+
+func TestProperties(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := endpoint.SetStunServerPort(3478); err != nil {
+		t.Fatal(err)
+	}
+	if port, err := endpoint.GetStunServerPort(); err != nil || port != 3478 {
+		t.Errorf("got %d, %v, want 3478", port, err)
+	}
+
+	s.SetProperty(endpoint.Id, "stunServerAddress", "stun.example.com")
+	if addr, err := endpoint.GetStunServerAddress(); err != nil || addr != "stun.example.com" {
+		t.Errorf("got %q, %v, want stun.example.com", addr, err)
+	}
+}
+
+// TestPolymorphicProperty checks that getters decode the values into the
+// types the server sent them as, as methods do.
+func TestPolymorphicProperty(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player, err := kurento.NewPlayerEndpoint(pipeline, "file:///video.webm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetProperty(player.Id, "latestStats", []interface{}{
+		map[string]interface{}{"__type__": "PlayerStats", "type": "endpoint", "cacheSize": 3},
+		map[string]interface{}{"type": "element", "id": "a"},
+	})
+	stats, err := player.GetLatestStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d stats, want 2", len(stats))
+	}
+	if p, ok := stats[0].(*kurento.PlayerStats); !ok || p.CacheSize != 3 {
+		t.Errorf("got %#v, want a PlayerStats", stats[0])
+	}
+	if e, ok := stats[1].(*kurento.ElementStats); !ok || e.Id != "a" {
+		t.Errorf("got %#v, want an ElementStats", stats[1])
+	}
+}
+
+func TestEmit(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates := make(chan kurento.IceCandidate, 1)
+	sub, err := endpoint.OnIceCandidateFound(func(ev kurento.IceCandidateFoundEvent) {
+		candidates <- ev.Candidate
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := s.Emit(endpoint.Id, "IceCandidateFound", map[string]interface{}{
+		"candidate": map[string]interface{}{"candidate": "candidate:1", "sdpMid": "0"},
+	})
+	if n != 1 {
+		t.Fatalf("event sent to %d subscriptions, want 1", n)
+	}
+	select {
+	case candidate := <-candidates:
+		if candidate.Candidate != "candidate:1" {
+			t.Errorf("got %+v, want candidate:1", candidate)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not received")
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Emit(endpoint.Id, "IceCandidateFound", nil); n != 0 {
+		t.Errorf("event sent to %d subscriptions after unsubscribe", n)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	s, c := dial(t)
+
+	pipeline, err := kurento.NewMediaPipeline(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := kurento.NewWebRtcEndpoint(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := endpoint.OnIceCandidateFound(func(kurento.IceCandidateFoundEvent) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if objs := s.Objects(""); len(objs) != 0 {
+		t.Errorf("got %d objects after release, want 0", len(objs))
+	}
+	if n := s.Emit(endpoint.Id, "IceCandidateFound", nil); n != 0 {
+		t.Errorf("event of a released object sent to %d subscriptions", n)
+	}
+}
+
+func TestServerManager(t *testing.T) {
+	s, c := dial(t)
+	s.SetProperty(kurentotest.ServerManagerId, "info", map[string]interface{}{
+		"version": "6.7.1",
+		"modules": []interface{}{
+			map[string]interface{}{"name": "core", "version": "6.7.1"},
+		},
+	})
+	s.Handle("ServerManager", "getKmd", func(obj *kurentotest.Object, params map[string]interface{}) (interface{}, error) {
+		return `{"name": "` + params["moduleName"].(string) + `"}`, nil
+	})
+
+	manager := c.ServerManager()
+	info, err := manager.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "6.7.1" || len(info.Modules) != 1 || info.Modules[0].Name != "core" {
+		t.Errorf("got %+v, want version 6.7.1 with the core module", info)
+	}
+	if kmd, err := manager.GetKmd("core"); err != nil || kmd != `{"name": "core"}` {
+		t.Errorf("got %s, %v, want the kmd of core", kmd, err)
+	}
+	if n := len(s.Objects("")); n != 0 {
+		t.Errorf("got %d objects, want the server manager not to be listed", n)
+	}
+}
+
+func TestDropConnections(t *testing.T) {
+	s, c := dial(t)
+
+	reconnected := make(chan bool, 1)
+	c.OnReconnect(func(resumed bool) { reconnected <- resumed })
+	session := c.SessionId()
+	if !strings.HasPrefix(session, "session-") {
+		t.Fatalf("got session %q", session)
+	}
+
+	s.DropConnections()
+	select {
+	case resumed := <-reconnected:
+		if !resumed || c.SessionId() != session {
+			t.Errorf("session %q not resumed, got %q", session, c.SessionId())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected")
+	}
+}
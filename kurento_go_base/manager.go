@@ -0,0 +1,14 @@
+package kurento
+
+// Id of the ServerManager of every media server
+const serverManagerId = "manager_ServerManager"
+
+// ServerManager returns the ServerManager of the media server, describing
+// it and the modules it runs. It exists as long as the server, and is not
+// created by clients.
+func (c *Connection) ServerManager() *ServerManager {
+	m := &ServerManager{}
+	m.setConnection(c)
+	m.setId(serverManagerId)
+	return m
+}
@@ -0,0 +1,116 @@
+package kurento
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Option configures a Connection, see Dial.
+type Option func(*options)
+
+type options struct {
+	origin      string
+	path        string
+	tls         *tls.Config
+	header      http.Header
+	dialer      *net.Dialer
+	logger      *log.Logger
+	keepalive   time.Duration
+	missedPongs int
+}
+
+// WithOrigin sets the origin sent to the server. Defaults to
+// "http://127.0.0.1".
+func WithOrigin(origin string) Option {
+	return func(o *options) {
+		o.origin = origin
+	}
+}
+
+// WithPath sets the path of the server endpoint, used when the url given
+// to Dial has none. Defaults to "/kurento".
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of "wss://" connections.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithHeader sets additional headers sent to the server.
+func WithHeader(header http.Header) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+// WithDialer sets the dialer used to connect, and reconnect, to the server.
+func WithDialer(dialer *net.Dialer) Option {
+	return func(o *options) {
+		o.dialer = dialer
+	}
+}
+
+// WithLogger sets the logger of the connection. By default, the connection
+// only logs in debug mode, to the standard logger.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithKeepalive sets the interval between the pings sent to the server, and
+// the number of pings it can miss before the connection is made again.
+// Defaults to 10 seconds and 3 pings. A zero interval disables pings.
+func WithKeepalive(interval time.Duration, missed int) Option {
+	return func(o *options) {
+		o.keepalive = interval
+		o.missedPongs = missed
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		origin:      "http://127.0.0.1",
+		path:        "/kurento",
+		keepalive:   10 * time.Second,
+		missedPongs: 3,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Return the configuration of websockets to the server at "rawurl".
+func (o *options) websocketConfig(rawurl string) (*websocket.Config, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = o.path
+	}
+
+	config, err := websocket.NewConfig(u.String(), o.origin)
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = o.tls
+	config.Dialer = o.dialer
+	for key, values := range o.header {
+		config.Header[key] = append([]string(nil), values...)
+	}
+	return config, nil
+}
@@ -0,0 +1,169 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Delays between attempts to reconnect, doubled after each failure
+const (
+	reconnectMinDelay = 100 * time.Millisecond
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// Time given to the server to resume the session and the subscriptions
+const resumeTimeout = 10 * time.Second
+
+// ErrDisconnected is returned by requests when the connection to the server
+// is lost. They may not have been handled by the server, and can be retried
+// once reconnected.
+var ErrDisconnected = errors.New("disconnected from the server")
+
+// OnDisconnect sets a function called when the connection to the server is
+// lost. Requests fail with ErrDisconnected until it is reconnected.
+func (c *Connection) OnDisconnect(f func(err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onDisconnect = f
+}
+
+// OnReconnect sets a function called once reconnected to the server.
+// "resumed" is false if the server lost the session: the objects created
+// before are lost too, and their subscriptions are dropped.
+func (c *Connection) OnReconnect(f func(resumed bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReconnect = f
+}
+
+// Open a websocket to the server.
+func (c *Connection) dial(ctx context.Context) (*websocket.Conn, error) {
+	return c.config.DialContext(ctx)
+}
+
+// reconnect redials the server until it answers, waiting longer after each
+// failure. Then it resumes the session and the subscriptions. It gives up
+// when the connection is closed.
+func (c *Connection) reconnect(cause error) {
+	c.mu.Lock()
+	onDisconnect := c.onDisconnect
+	c.mu.Unlock()
+	if onDisconnect != nil {
+		onDisconnect(cause)
+	}
+
+	delay := reconnectMinDelay
+	var ws *websocket.Conn
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), reconnectMaxDelay)
+		var err error
+		ws, err = c.dial(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		c.log("Cannot reconnect: ", err)
+		if delay *= 2; delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+
+	// Close may be waiting for writeMu, to close the previous websocket
+	c.writeMu.Lock()
+	if c.isClosed() {
+		c.writeMu.Unlock()
+		ws.Close()
+		return
+	}
+	c.ws = ws
+	c.writeMu.Unlock()
+	c.mu.Lock()
+	if !c.closed {
+		c.err = nil
+	}
+	c.mu.Unlock()
+	go c.handleResponse(ws)
+
+	resumed, err := c.resume()
+	if err != nil {
+		// reconnect again
+		ws.Close()
+		return
+	}
+	if resumed {
+		c.resubscribe()
+	} else {
+		c.events.removeAll()
+	}
+
+	c.mu.Lock()
+	onReconnect := c.onReconnect
+	c.mu.Unlock()
+	if onReconnect != nil {
+		onReconnect(resumed)
+	}
+}
+
+// Code of the errors sent for an invalid or expired session, also by the
+// servers that don't send their type
+const invalidSessionCode = 40007
+
+// Return true if "err" tells that the server lost the session.
+func sessionLost(err error) bool {
+	var serverErr *Error
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	return errors.Is(err, ErrInvalidSession) || serverErr.Code == invalidSessionCode
+}
+
+// resume connects again to the session, so that the server binds it to
+// the new websocket. It returns false if the server lost it, and an error
+// if the server didn't answer or failed otherwise, to try again.
+func (c *Connection) resume() (bool, error) {
+	sessionId := c.SessionId()
+
+	ctx, cancel := context.WithTimeout(context.Background(), resumeTimeout)
+	defer cancel()
+	err := c.connect(ctx)
+
+	if sessionId != "" && sessionLost(err) {
+		c.mu.Lock()
+		if c.sessionId == sessionId {
+			c.sessionId = ""
+		}
+		c.mu.Unlock()
+		return false, c.connect(ctx)
+	}
+	return err == nil, err
+}
+
+// resubscribe subscribes again to the events of every subscription.
+func (c *Connection) resubscribe() {
+	for _, s := range c.events.all() {
+		// the events sent before the new id is known are not lost
+		c.events.bind(s, "")
+		ctx, cancel := context.WithTimeout(context.Background(), resumeTimeout)
+		response, err := s.object.request(ctx, subscribeRequest(s.Type, s.object.Id))
+		cancel()
+
+		var id string
+		if err == nil {
+			err = s.object.decodeValue(response, &id)
+		}
+		if err != nil {
+			c.log("Cannot subscribe again to ", s.Type, s.object.Id, err)
+			continue
+		}
+		c.events.bind(s, id)
+	}
+}
@@ -0,0 +1,61 @@
+package kurento
+
+import (
+	"context"
+	"time"
+)
+
+// connect sends "connect" to the server. Request adds the id of the session,
+// if any, so that the server binds that session to the current websocket.
+// Otherwise the server creates one, and gives its id in the response.
+func (c *Connection) connect(ctx context.Context) error {
+	_, err := c.Request(ctx, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "connect",
+		"params":  map[string]interface{}{},
+	})
+	return err
+}
+
+// keepalive pings the server every "interval" until the connection is
+// closed. The websocket is closed, to be made again, when "missed" pings in
+// a row are not answered in time.
+func (c *Connection) keepalive(interval time.Duration, missed int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	n := 0
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		_, err := c.Request(ctx, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "ping",
+			"params": map[string]interface{}{
+				"interval": int64(interval / time.Millisecond),
+			},
+		})
+		cancel()
+
+		// errors sent by the server are answers too, and disconnections are
+		// handled by reconnect
+		if err != context.DeadlineExceeded {
+			n = 0
+			continue
+		}
+		if n++; n < missed {
+			continue
+		}
+
+		c.log("The server missed ", n, " pings, reconnecting")
+		n = 0
+		c.writeMu.Lock()
+		c.ws.Close()
+		c.writeMu.Unlock()
+	}
+}
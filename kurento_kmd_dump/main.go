@@ -0,0 +1,292 @@
+// Command kurento_kmd_dump records the kmd files of the modules run by a
+// media server, to generate a client matching it, even offline:
+//
+//	go run ./kurento_kmd_dump -kms ws://127.0.0.1:8888/kurento -out kmd
+//	go run main.go -kmd 'kmd/*.kmd.json'
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+var (
+	kms     = flag.String("kms", "ws://127.0.0.1:8888", "url of the media server")
+	out     = flag.String("out", "kmd", "directory of the kmd files, replaced by the ones of the server")
+	timeout = flag.Duration("timeout", 30*time.Second, "timeout of the dump")
+)
+
+// Id of the ServerManager of every media server
+const serverManagerId = "manager_ServerManager"
+
+func main() {
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	kmd, err := dumpKmd(ctx, *kms)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeKmd(*out, kmd); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// The dump doesn't use the kurento package, as it is generated from the kmd
+// files it writes: it speaks the JSON-RPC protocol of the server itself.
+type client struct {
+	ws        *websocket.Conn
+	lastId    int
+	sessionId string
+}
+
+// Error returned by the server
+type rpcError struct {
+	Code    int64
+	Message string
+	Data    json.RawMessage
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+}
+
+// Connect to the media server at "rawurl". The path defaults to "/kurento",
+// as for kurento.Dial.
+func dial(ctx context.Context, rawurl string) (*client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/kurento"
+	}
+	config, err := websocket.NewConfig(u.String(), "http://127.0.0.1")
+	if err != nil {
+		return nil, err
+	}
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		ws.SetDeadline(deadline)
+	}
+
+	c := &client{ws: ws}
+	if err := c.call("connect", nil, nil); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	return c, nil
+}
+
+// Send the request "method" with "params", and decode the value of its
+// result into "value", if not nil. The notifications sent meanwhile are
+// ignored.
+func (c *client) call(method string, params map[string]interface{}, value interface{}) error {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	if c.sessionId != "" {
+		params["sessionId"] = c.sessionId
+	}
+	c.lastId++
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      c.lastId,
+		"method":  method,
+		"params":  params,
+	}
+	if err := websocket.JSON.Send(c.ws, req); err != nil {
+		return err
+	}
+
+	for {
+		var response struct {
+			Id     *int
+			Result *struct {
+				Value     json.RawMessage
+				SessionId string
+			}
+			Error *rpcError
+		}
+		if err := websocket.JSON.Receive(c.ws, &response); err != nil {
+			return err
+		}
+		if response.Id == nil || *response.Id != c.lastId {
+			continue
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		if response.Result == nil {
+			return fmt.Errorf("%s: no result", method)
+		}
+		if response.Result.SessionId != "" {
+			c.sessionId = response.Result.SessionId
+		}
+		if value == nil {
+			return nil
+		}
+		return json.Unmarshal(response.Result.Value, value)
+	}
+}
+
+// Invoke "operation" of the ServerManager with "params".
+func (c *client) invoke(operation string, params map[string]interface{}, value interface{}) error {
+	return c.call("invoke", map[string]interface{}{
+		"object":          serverManagerId,
+		"operation":       operation,
+		"operationParams": params,
+	}, value)
+}
+
+// Return the kmd of every module the media server at "rawurl" runs, by
+// module name, as returned by ServerManager.getKmd.
+func dumpKmd(ctx context.Context, rawurl string) (map[string]string, error) {
+	c, err := dial(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer c.ws.Close()
+
+	var info struct {
+		Modules []struct {
+			Name string
+		}
+	}
+	if err := c.invoke("getInfo", nil, &info); err != nil {
+		return nil, fmt.Errorf("info of the server: %w", err)
+	}
+
+	ret := make(map[string]string, len(info.Modules))
+	for _, m := range info.Modules {
+		var kmd string
+		if err := c.invoke("getKmd", map[string]interface{}{"moduleName": m.Name}, &kmd); err != nil {
+			return nil, fmt.Errorf("kmd of module %s: %w", m.Name, err)
+		}
+		ret[m.Name] = kmd
+	}
+	return ret, nil
+}
+
+// rename renames files for writeKmd. Tests replace it to make it fail.
+var rename = os.Rename
+
+// Write the "kmd" of each module to "dir", as "<module>.kmd.json". The kmd
+// files of the modules the server doesn't run any more are removed. Every
+// file is written to a temporary file first, then the files replaced or
+// removed are moved aside, and restored if one of the renames fails, so
+// that "dir" is left as it was.
+func writeKmd(dir string, kmd map[string]string) error {
+	var names []string
+	for name := range kmd {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid module name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmps := make(map[string]string)
+	defer func() {
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}()
+
+	var dsts []string
+	for _, name := range names {
+		dst := filepath.Join(dir, name+".kmd.json")
+		dsts = append(dsts, dst)
+		f, err := ioutil.TempFile(dir, "."+filepath.Base(dst)+".")
+		if err != nil {
+			return err
+		}
+		tmps[dst] = f.Name()
+		_, err = f.WriteString(kmd[name])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(f.Name(), 0644); err != nil {
+			return err
+		}
+	}
+	old, err := filepath.Glob(filepath.Join(dir, "*.kmd.json"))
+	if err != nil {
+		return err
+	}
+
+	backups := make(map[string]string)
+	var written []string
+	restore := func() {
+		for _, dst := range written {
+			os.Remove(dst)
+		}
+		for dst, backup := range backups {
+			os.Rename(backup, dst)
+		}
+	}
+	moveAside := func(dst string) error {
+		if _, err := os.Lstat(dst); os.IsNotExist(err) {
+			return nil
+		}
+		backup := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".old")
+		if err := rename(dst, backup); err != nil {
+			return err
+		}
+		backups[dst] = backup
+		return nil
+	}
+
+	for _, dst := range dsts {
+		if err := moveAside(dst); err != nil {
+			restore()
+			return err
+		}
+		if err := rename(tmps[dst], dst); err != nil {
+			restore()
+			return err
+		}
+		delete(tmps, dst)
+		written = append(written, dst)
+	}
+	// the files of the other modules are removed once all are written
+	for _, path := range old {
+		if _, ok := kmd[strings.TrimSuffix(filepath.Base(path), ".kmd.json")]; ok {
+			continue
+		}
+		if err := moveAside(path); err != nil {
+			restore()
+			return err
+		}
+	}
+	for _, backup := range backups {
+		os.Remove(backup)
+	}
+	for _, dst := range dsts {
+		fmt.Println("Dumped", dst)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"kurento-client-go-generator/kurento/kurentotest"
+)
+
+func TestDumpKmd(t *testing.T) {
+	s := kurentotest.NewServer()
+	defer s.Close()
+	s.SetProperty(kurentotest.ServerManagerId, "info", map[string]interface{}{
+		"version": "6.7.1",
+		"modules": []interface{}{
+			map[string]interface{}{"name": "core", "version": "6.7.1"},
+			map[string]interface{}{"name": "myfilter", "version": "1.0.0"},
+		},
+	})
+	s.Handle("ServerManager", "getKmd", func(obj *kurentotest.Object, params map[string]interface{}) (interface{}, error) {
+		return `{"name": "` + params["moduleName"].(string) + `"}`, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	kmd, err := dumpKmd(ctx, s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the files of the modules the server doesn't run are removed
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "old.kmd.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeKmd(dir, kmd); err != nil {
+		t.Fatal(err)
+	}
+	got := readDir(t, dir)
+	want := map[string]string{
+		"core.kmd.json":     `{"name": "core"}`,
+		"myfilter.kmd.json": `{"name": "myfilter"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDumpKmdError(t *testing.T) {
+	s := kurentotest.NewServer()
+	defer s.Close()
+	s.SetProperty(kurentotest.ServerManagerId, "info", map[string]interface{}{
+		"modules": []interface{}{map[string]interface{}{"name": "core"}},
+	})
+	s.Handle("ServerManager", "getKmd", func(obj *kurentotest.Object, params map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("no kmd")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := dumpKmd(ctx, s.URL)
+	var e *rpcError
+	if !errors.As(err, &e) || e.Message != "no kmd" {
+		t.Errorf("got %v, want the error of the server", err)
+	}
+}
+
+// Return the contents of the files of "dir", by name.
+func readDir(t *testing.T, dir string) map[string]string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := make(map[string]string)
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret[f.Name()] = string(data)
+	}
+	return ret
+}
+
+func TestWriteKmdFailure(t *testing.T) {
+	defer func() { rename = os.Rename }()
+	dir := t.TempDir()
+	old := map[string]string{
+		"core.kmd.json":  `{"name": "core", "version": "1"}`,
+		"other.kmd.json": `{"name": "other"}`,
+	}
+	for name, kmd := range old {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(kmd), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rename = func(from, to string) error {
+		if filepath.Base(to) == "myfilter.kmd.json" {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	kmd := map[string]string{
+		"core":     `{"name": "core", "version": "2"}`,
+		"myfilter": `{"name": "myfilter"}`,
+	}
+	if err := writeKmd(dir, kmd); err == nil || err.Error() != "disk full" {
+		t.Fatalf("got %v, want the error of rename", err)
+	}
+	if got := readDir(t, dir); !reflect.DeepEqual(got, old) {
+		t.Errorf("got files %v, want %v", got, old)
+	}
+}
@@ -3,98 +3,376 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"html"
+	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
-const packageTemplate = `package kurento
+const packageTemplate = `{{ .Doc }}
+package {{ .Package }}
+
 {{ .Content }}
 `
 
 const strTemplate = `
-{{ define "Arguments" }}{{ range $i, $e := .Params }}{{ if $i }} , {{ end }}{{ $e.name }} {{ $e.type | checkElement }}{{ end }}{{ end }}
+{{ define "Arguments" }}{{ range $i, $e := .Params }}{{ if $i }} , {{ end }}{{ $e.name }} {{ $e.type }}{{ end }}{{ end }}
+{{ define "Names" }}{{ range $i, $e := .Params }}{{ if $i }} , {{ end }}{{ $e.name }}{{ end }}{{ end }}
+{{ define "CtxArguments" }}ctx context.Context{{ if .Params }}, {{ template "Arguments" . }}{{ end }}{{ end }}
+{{ define "CtorArguments" }}{{ if not .Parent }}c *{{ runtime }}Connection, {{ end }}{{ range .Params }}{{ if not .optional }}{{ .name }} {{ .type }}, {{ end }}{{ end }}{{ end }}
+{{ define "CtorNames" }}{{ if not .Parent }}c, {{ end }}{{ range .Params }}{{ if not .optional }}{{ .name }}, {{ end }}{{ end }}{{ end }}
+{{ define "OperationParams" }}{{ if .Params }}params{{ else }}nil{{ end }}{{ end }}
+{{ define "Call" }}
+{{- if .Return.type }}
+	var ret {{ .Return.type }}
+	{{- if .Return.polymorphic }}
+	{{- if eq .Return.shape "map" }}
+	values := map[string]json.RawMessage{}
+	{{- else if eq .Return.shape "[]" }}
+	values := []json.RawMessage{}
+	{{- else }}
+	var values json.RawMessage
+	{{- end }}
+	if err := elem.Invoke(ctx, "{{ .Name }}", {{ template "OperationParams" . }}, &values); err != nil {
+		return ret, err
+	}
+	{{- if .Return.shape }}
+	ret = make({{ .Return.type }}, len(values))
+	for i, value := range values {
+		v, err := {{ .Return.polymorphic }}(value)
+		if err != nil {
+			return ret, err
+		}
+		ret[i] = v
+	}
+	return ret, nil
+	{{- else }}
+	return {{ .Return.polymorphic }}(values)
+	{{- end }}
+	{{- else }}
+	err := elem.Invoke(ctx, "{{ .Name }}", {{ template "OperationParams" . }}, &ret)
+	return ret, err
+	{{- end }}
+{{- else }}
+	return elem.Invoke(ctx, "{{ .Name }}", {{ template "OperationParams" . }}, nil)
+{{- end }}
+{{- end }}
 {{ $name := .Name}}
 
 {{/* Generator interface then struct */}}
-{{ if ne .Name "MediaObject" }}
+// I{{ .Name }} is the interface of {{ .Name }}, including the methods it inherits. It is implemented by the fakes of package {{ fakes }}.
 type I{{ .Name }} interface {
+	{{- with .Extends }}
+	{{ . | iface }}
+	{{- else }}
+	// Implemented by the runtime
+	String() string
+	Release() error
+	ReleaseCtx(context.Context) error
+	{{- end }}
 	{{ range .Methods }}
 	{{ .Name | title }}({{ template "Arguments" .}})({{ if .Return.type }}{{ .Return.type }},{{ end }} error)
+	{{ .Name | title }}Ctx({{ template "CtxArguments" .}})({{ if .Return.type }}{{ .Return.type }},{{ end }} error)
+	{{ end }}
+	{{ range .Properties }}
+	Get{{ .name | title }}() ({{ .type }}, error)
+	Get{{ .name | title }}Ctx(context.Context) ({{ .type }}, error)
+	{{ if not (or .readOnly .final) }}Set{{ .name | title }}({{ .name }} {{ .setType }}) error
+	Set{{ .name | title }}Ctx(ctx context.Context, {{ .name }} {{ .setType }}) error{{ end }}
+	{{ end }}
+	{{ range .Events }}
+	On{{ . }}(func({{ . | event }})) (*{{ runtime }}Subscription, error)
+	On{{ . }}Ctx(context.Context, func({{ . | event }})) (*{{ runtime }}Subscription, error)
 	{{ end }}
 }
-{{ end }}
 
 {{ .Doc }}
 type {{ .Name }} struct {
 	{{ if eq .Name "MediaObject" }}connection *Connection
+
+	// Identifier given by the server
+	Id string
+
+	// Object that created this one, and the objects created by this one
+	Parent IMediaObject
+	Childs []IMediaObject
+
+	// Guards Childs
+	mu sync.Mutex
+
+	// Set to 1 once released, see Release
+	released int32
 	{{ else }} {{ .Extends }}
 	{{ end }}
-	
-	{{ range .Properties }}
-	{{ .doc }}
-	{{ .name | title }} {{ .type }}
-	{{ end }}
 }
 
+{{ if runtime }}
+// Every class implements its interface, and is created by the runtime as
+// a ModuleClass
+var (
+	_ I{{ .Name }}             = (*{{ .Name }})(nil)
+	_ {{ runtime }}ModuleClass = (*{{ .Name }})(nil)
+)
+
+// FinalProperties returns the properties that can only be set by "Create".
+func (elem *{{ .Name }}) FinalProperties() []string {
+	return []string{ {{ range .FinalProperties }}"{{ . }}", {{ end }} }
+}
+
+// ConstructorParams returns the constructor params, used by "Create".
+func (elem *{{ .Name }}) ConstructorParams(from {{ runtime }}IMediaObject, options map[string]interface{}) map[string]interface{} {
+{{- else }}
+// Every class implements its interface, and is created and used by the
+// runtime as a mediaObject
+var (
+	_ I{{ .Name }} = (*{{ .Name }})(nil)
+	_ mediaObject  = (*{{ .Name }})(nil)
+)
+
+{{ if .FinalMethod }}
+// Return properties that can only be set by "Create".
+func (elem *{{ .Name }}) finalProperties() []string {
+	return []string{ {{ range .FinalProperties }}"{{ . }}", {{ end }} }
+}
+{{ end }}
+
 // Return Constructor Params to be called by "Create".
-func (elem *{{ .Name }}) getConstuctorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
-	{{ if len .Constructor.Params }}
+func (elem *{{ .Name }}) getConstructorParams(from mediaObject, options map[string]interface{}) map[string]interface{} {
+{{- end }}
+	ret := map[string]interface{}{
+		{{ with .Constructor }}{{ with .Parent }}"{{ . }}": from,{{ end }}{{ end }}
+	}
+	for key, val := range options {
+		ret[key] = val
+	}
+	return ret
+}
+
+{{ range .Defaults }}
+{{ .Doc }}
+{{ if .Const }}const {{ .Name }} {{ .Type }} = {{ .Value }}{{ else }}var {{ .Name }} = {{ .Value }}{{ end }}
+{{ end }}
 
-	// Create basic constructor params
-	ret := map[string]interface{} {
-		{{ range .Constructor.Params }}{{ if eq .type "string" "float64" "boolean" "int" }}"{{ .name }}" : {{ .defaultValue }},
-		{{ else }} "{{ .name }}" : fmt.Sprintf("%s", from),
+{{ with .Constructor }}
+// {{ $name }}Option sets an optional param of New{{ $name }}.
+type {{ $name }}Option func(params map[string]interface{})
+
+{{ range .Params }}{{ if .optional }}
+{{ with .doc }}{{ . }}
+//
+{{ end }}{{ with .default }}// Defaults to [{{ . }}] when not set.{{ else }}// The media server uses its own default when it is not set.{{ end }}
+func {{ $name }}{{ .name | title }}({{ .name }} {{ .type }}) {{ $name }}Option {
+	return func(params map[string]interface{}) {
+		params["{{ .name }}"] = {{ .name }}
+	}
+}
+{{ end }}{{ end }}
+
+{{ .Doc }}
+func New{{ $name }}({{ template "CtorArguments" . }}opts ...{{ $name }}Option) (*{{ $name }}, error) {
+	return New{{ $name }}Ctx(context.Background(), {{ template "CtorNames" . }}opts...)
+}
+
+// New{{ $name }}Ctx is like New{{ $name }}, with a context that can cancel the call.
+func New{{ $name }}Ctx(ctx context.Context, {{ template "CtorArguments" . }}opts ...{{ $name }}Option) (*{{ $name }}, error) {
+	params := map[string]interface{}{
+		{{ range .Params }}{{ if not .optional }}"{{ .name }}": {{ .name }},
 		{{ end }}{{ end }}
 	}
-	
-	mergeOptions(ret, options)
-	return ret
-	{{ else }}return options
-	{{ end }}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	elem := &{{ $name }}{}
+	{{ if .Parent }}parent, err := {{ runtime }}ObjectOf({{ .Parent }})
+	if err != nil {
+		return nil, err
+	}
+	if err := parent.CreateCtx(ctx, elem, params); err != nil {
+		return nil, err
+	}{{ else }}if err := c.CreateCtx(ctx, elem, params); err != nil {
+		return nil, err
+	}{{ end }}
+	return elem, nil
 }
+{{ end }}
 
 {{ range .Methods }}
-{{ .Doc }} 
-{{ if .Return.doc }}
-// Returns
-{{ .Return.doc }}
-{{ end }}
+{{ .Doc }}
 func (elem *{{$name}}) {{ .Name | title }}({{ template "Arguments" . }}) ({{ if .Return.type }}{{ .Return.type }}, {{ end}} error) {
-	req := elem.getInvokeRequest()
-	{{ if .Params }}
-	params := make(map[string]interface{})
-	{{ range  .Params }}
-	setIfNotEmpty(params, "{{ .name }}", {{ .name }})
-	{{end}}
+	return elem.{{ .Name | title }}Ctx(context.Background(){{ if .Params }}, {{ template "Names" . }}{{ end }})
+}
+
+// {{ .Name | title }}Ctx is like {{ .Name | title }}, with a context that can cancel the call.
+func (elem *{{$name}}) {{ .Name | title }}Ctx({{ template "CtxArguments" . }}) ({{ if .Return.type }}{{ .Return.type }}, {{ end}} error) {
+	{{- if .Params }}
+	params := map[string]interface{}{
+		{{ range .Params }}{{ if not .optional }}"{{ .name }}": {{ .name }},
+		{{ end }}{{ end }}
+	}
+	{{- range .Params }}{{ if .optional }}
+	if {{ .name }} != nil {
+		params["{{ .name }}"] = {{ .name }}
+	}
+	{{- end }}{{ end }}
 	{{ end }}
+	// call the server and wait for the response
+	{{- template "Call" . }}
+}
+{{ end }}
+
+{{ range .Properties }}
+{{ .doc }}
+func (elem *{{$name}}) Get{{ .name | title }}() ({{ .type }}, error) {
+	return elem.Get{{ .name | title }}Ctx(context.Background())
+}
+
+// Get{{ .name | title }}Ctx is like Get{{ .name | title }}, with a context that can cancel the call.
+func (elem *{{$name}}) Get{{ .name | title }}Ctx(ctx context.Context) ({{ .type }}, error) {
+	// call the server and wait for the response
+	{{- template "Call" .getter }}
+}
+
+{{ if not (or .readOnly .final) }}
+{{ .doc }}
+func (elem *{{$name}}) Set{{ .name | title }}({{ .name }} {{ .setType }}) error {
+	return elem.Set{{ .name | title }}Ctx(context.Background(), {{ .name }})
+}
+
+// Set{{ .name | title }}Ctx is like Set{{ .name | title }}, with a context that can cancel the call.
+func (elem *{{$name}}) Set{{ .name | title }}Ctx(ctx context.Context, {{ .name }} {{ .setType }}) error {
+	// call the server and wait for the response
+	return elem.Invoke(ctx, "set{{ .name | title }}", map[string]interface{}{
+		"{{ .name }}": {{ .name }},
+	}, nil)
+}
+{{ end }}
+{{ end }}
 
-	req["params"] = map[string]interface{} {
-		"operation": "{{ .Name }}",
-		"object": elem.Id, 
-		{{ if .Params }}
-		"operationParams": params,
-		{{ end }}
-	}
-
-	// call server and and wait response
-	response := <-elem.connection.Request(req)
-	{{ if .Return }}
-	{{ .Return.doc }}
-		{{ if eq .Return.type "string" "int" "float64" "boolean" }}
-		return response.Result["value"], response.Error
-		{{ else }}
-		ret := {{ .Return.type }}{}
-		return ret, response.Error
-		{{ end }}
-	{{ else }}
-	return response.Error
+{{ range .Events }}
+// On{{ . }} subscribes "cb" to {{ . }} events raised by the object. Call Unsubscribe on the returned Subscription to stop receiving them.
+func (elem *{{$name}}) On{{ . }}(cb func({{ . | event }})) (*{{ runtime }}Subscription, error) {
+	return elem.On{{ . }}Ctx(context.Background(), cb)
+}
+
+// On{{ . }}Ctx is like On{{ . }}, with a context that can cancel the call.
+func (elem *{{$name}}) On{{ . }}Ctx(ctx context.Context, cb func({{ . | event }})) (*{{ runtime }}Subscription, error) {
+	return elem.Subscribe(ctx, "{{ . }}", cb)
+}
+{{ end }}
+`
+
+const fakeTemplate = `
+{{ define "Arguments" }}{{ range $i, $e := .Params }}{{ if $i }} , {{ end }}{{ $e.name }} {{ $e.type }}{{ end }}{{ end }}
+{{ define "Names" }}{{ range $i, $e := .Params }}{{ if $i }} , {{ end }}{{ $e.name }}{{ end }}{{ end }}
+{{ define "CtxArguments" }}ctx context.Context{{ if .Params }}, {{ template "Arguments" . }}{{ end }}{{ end }}
+{{ define "Results" }}({{ if .Return.type }}{{ .Return.type }}, {{ end }}error){{ end }}
+{{ $name := .Name }}
+
+// {{ .Name }} is a fake {{ .Name | qualify | iface }}.
+type {{ .Name }} struct {
+	{{ if eq .Name "MediaObject" }}recorder
+
+	// Returned by String
+	Id string
+
+	ReleaseFunc func(ctx context.Context) error
+	{{ else }}{{ .Extends | fake }}
+	{{ end }}
+	{{ range .Methods }}{{ .Name | title }}Func func({{ template "CtxArguments" . }}) {{ template "Results" . }}
 	{{ end }}
+	{{ range .Properties }}Get{{ .name | title }}Func func(ctx context.Context) ({{ .type }}, error)
+	{{ if not (or .readOnly .final) }}Set{{ .name | title }}Func func(ctx context.Context, {{ .name }} {{ .setType }}) error
+	{{ end }}{{ end }}
+	{{ range .Events }}On{{ . }}Func func(ctx context.Context, cb func({{ . | event }})) (*{{ runtime }}Subscription, error)
+	{{ end }}
+}
+
+var _ {{ .Name | qualify | iface }} = (*{{ .Name }})(nil)
+
+{{ range .Methods }}
+func (f *{{ $name }}) {{ .Name | title }}({{ template "Arguments" . }}) {{ template "Results" . }} {
+	return f.{{ .Name | title }}Ctx(context.Background(){{ if .Params }}, {{ template "Names" . }}{{ end }})
+}
+
+func (f *{{ $name }}) {{ .Name | title }}Ctx({{ template "CtxArguments" . }}) {{ template "Results" . }} {
+	f.RecordCall("{{ .Name | title }}"{{ if .Params }}, {{ template "Names" . }}{{ end }})
+	if f.{{ .Name | title }}Func != nil {
+		return f.{{ .Name | title }}Func(ctx{{ if .Params }}, {{ template "Names" . }}{{ end }})
+	}
+	{{ if .Return.type }}var ret {{ .Return.type }}
+	return ret, nil{{ else }}return nil{{ end }}
+}
+{{ end }}
+
+{{ range .Properties }}
+func (f *{{ $name }}) Get{{ .name | title }}() ({{ .type }}, error) {
+	return f.Get{{ .name | title }}Ctx(context.Background())
+}
+
+func (f *{{ $name }}) Get{{ .name | title }}Ctx(ctx context.Context) ({{ .type }}, error) {
+	f.RecordCall("Get{{ .name | title }}")
+	if f.Get{{ .name | title }}Func != nil {
+		return f.Get{{ .name | title }}Func(ctx)
+	}
+	var ret {{ .type }}
+	return ret, nil
+}
+
+{{ if not (or .readOnly .final) }}
+func (f *{{ $name }}) Set{{ .name | title }}({{ .name }} {{ .setType }}) error {
+	return f.Set{{ .name | title }}Ctx(context.Background(), {{ .name }})
+}
+
+func (f *{{ $name }}) Set{{ .name | title }}Ctx(ctx context.Context, {{ .name }} {{ .setType }}) error {
+	f.RecordCall("Set{{ .name | title }}", {{ .name }})
+	if f.Set{{ .name | title }}Func != nil {
+		return f.Set{{ .name | title }}Func(ctx, {{ .name }})
+	}
+	return nil
+}
+{{ end }}
+{{ end }}
+
+{{ range .Events }}
+func (f *{{ $name }}) On{{ . }}(cb func({{ . | event }})) (*{{ runtime }}Subscription, error) {
+	return f.On{{ . }}Ctx(context.Background(), cb)
+}
+
+// On{{ . }}Ctx keeps "cb", to be called by Emit{{ . }}, unless On{{ . }}Func is set.
+func (f *{{ $name }}) On{{ . }}Ctx(ctx context.Context, cb func({{ . | event }})) (*{{ runtime }}Subscription, error) {
+	f.RecordCall("On{{ . }}", cb)
+	if f.On{{ . }}Func != nil {
+		return f.On{{ . }}Func(ctx, cb)
+	}
+	f.AddCallback("{{ . }}", cb)
+	return &{{ runtime }}Subscription{Type: "{{ . }}"}, nil
+}
+
+// Emit{{ . }} calls the callbacks given to On{{ . }}.
+func (f *{{ $name }}) Emit{{ . }}(ev {{ . | event }}) {
+	for _, cb := range f.Callbacks("{{ . }}") {
+		cb.(func({{ . | event }}))(ev)
+	}
 }
 {{ end }}
 `
@@ -114,23 +392,163 @@ const (
 	{{ end}}
 )
 {{ else }}
+{{ .Doc }}
 type {{ .Name }} struct {
-	{{ range .Properties}}{{ .name | title }} {{ .type }}
+	{{- if .Extends }}
+	{{ .Extends }}
+	{{ end }}{{ range .Properties}}
+	{{ .doc }}
+	{{ .name | title }} {{ .type }} ` + "`" + `json:"{{ .name }}{{ if .omitEmpty }},omitempty{{ end }}"` + "`" + `
+	{{ end }}
+}
+{{ if .Subtypes }}
+{{ $name := .Name }}
+// I{{ .Name }} is implemented by {{ .Name }} and by the types extending it.
+type I{{ .Name }} interface {
+	Get{{ .Name }}() *{{ .Name }}
+}
+
+// Get{{ .Name }} returns the {{ .Name }} part of the value.
+func (t *{{ .Name }}) Get{{ .Name }}() *{{ .Name }} {
+	return t
+}
+
+// Types that can be sent as {{ .Name }}, by kmd name
+var new{{ .Name }}Types = map[string]func() I{{ .Name }}{
+	"{{ .Name }}": func() I{{ .Name }} { return &{{ .Name }}{} },
+	{{ range .Subtypes }}"{{ . }}": func() I{{ $name }} { return &{{ . }}{} },
+	{{ end }}
+}
+{{ if .Discriminators }}
+// Types that can be sent as {{ .Name }}, by value of their "type" field
+var discriminated{{ .Name }}Types = map[string]string{
+	{{ range $k, $v := .Discriminators }}"{{ $k }}": "{{ $v }}",
 	{{ end }}
 }
 {{ end }}
+// DecodeI{{ .Name }} decodes a {{ .Name }} into the type it was sent as, given by its "__type__"{{ if .Discriminators }} or "type"{{ end }}.
+func DecodeI{{ .Name }}(data []byte) (I{{ .Name }}, error) {
+	head := struct {
+		Class string          ` + "`json:\"__type__\"`" + `
+		Kind  json.RawMessage ` + "`json:\"type\"`" + `
+	}{}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	newType, ok := new{{ .Name }}Types[head.Class]
+	{{ if .Discriminators }}if !ok {
+		// "type" is not a string in every complex type
+		var kind string
+		json.Unmarshal(head.Kind, &kind)
+		newType, ok = new{{ .Name }}Types[discriminated{{ .Name }}Types[kind]]
+	}
+	{{ end }}if !ok {
+		newType = new{{ .Name }}Types["{{ .Name }}"]
+	}
+
+	ret := newType()
+	err := json.Unmarshal(data, ret)
+	return ret, err
+}
+{{ end }}
+{{ end }}
+`
+
+const eventTemplate = `
+{{ .Doc }}
+type {{ .Name }}Event struct {
+	{{- range .Properties }}
+	{{ .doc }}
+	{{ .name | title }} {{ .type }} ` + "`" + `json:"{{ .name }}"` + "`" + `
+	{{ end }}
+}
 `
 
 const DOCLINELENGTH = 79
 
-var re = regexp.MustCompile(`(.+)\[\]`)
-var symbol = regexp.MustCompile(`<>`)
+// Kinds of the kmd definitions
+const (
+	classDef       = "class"
+	complexTypeDef = "complex type"
+	eventDef       = "event"
+)
+
+// A class, complex type or event, and the kmd file defining it.
+type definition struct {
+	kind  string
+	name  string
+	path  string
+	model interface{}
+}
+
+// DEFINITIONS registers every Go identifier declared for the kmd
+// definitions, so that each one is written once, in the file of the module
+// defining it.
+var DEFINITIONS = make(map[string]definition)
+
+// A kmd module, and the kmd files defining it
+type module struct {
+	Name    string
+	Version string
+	Imports []Import
+	paths   []string
+}
+
+// MODULES holds every kmd module found in the kmd files, by name.
+var MODULES = make(map[string]*module)
+
+// MODULEOF holds the name of the module of each kmd file, by path.
+var MODULEOF = make(map[string]string)
+
+// PACKAGE is the name of the Go package being generated. Types defined in
+// other packages are qualified with the name of theirs.
+var PACKAGE string
+
+// CPXTYPEMODELS holds every complex type found in the kmd files, by name.
+var CPXTYPEMODELS = make(map[string]ComplexType)
+
+// CLASSES holds every remote class found in the kmd files, by name.
+var CLASSES = make(map[string]Class)
+
+// CPXEXTENDS holds the parent of each complex type that extends another.
+var CPXEXTENDS = make(map[string]string)
+
+// Subtypes of polymorphic complex types selected by the value of their
+// "type" field, used when the server doesn't send their "__type__".
+var typeDiscriminators = map[string]map[string]string{
+	"Stats": {
+		"inboundrtp":      "RTCInboundRTPStreamStats",
+		"outboundrtp":     "RTCOutboundRTPStreamStats",
+		"session":         "RTCPeerConnectionStats",
+		"datachannel":     "RTCDataChannelStats",
+		"track":           "RTCMediaStreamTrackStats",
+		"transport":       "RTCTransportStats",
+		"candidatepair":   "RTCIceCandidatePairStats",
+		"localcandidate":  "RTCIceCandidateAttributes",
+		"remotecandidate": "RTCIceCandidateAttributes",
+		"element":         "ElementStats",
+		"endpoint":        "EndpointStats",
+	},
+}
 
-var CPXTYPES []string
+// EVENTS holds every event found in the kmd files, by name, so that
+// inherited properties can be resolved across files.
+var EVENTS = make(map[string]Event)
 
 type Core struct {
+	Name          string
+	Version       string
+	Imports       []Import
 	RemoteClasses []Class
 	ComplexTypes  []ComplexType
+	Events        []Event
+}
+
+// Module imported by a kmd module, and the versions it requires, as
+// "^6.7.0"
+type Import struct {
+	Name    string
+	Version string
 }
 
 type Class struct {
@@ -141,13 +559,35 @@ type Class struct {
 	Extends     string
 	Methods     []Method
 	Events      []string
-	Constructor Constructor
+	Constructor *Constructor
+
+	// Computed, see finalProperties(). FinalMethod is set if the class
+	// declares the unexported method returning them, see formatClass
+	FinalProperties []string `json:"-"`
+	FinalMethod     bool     `json:"-"`
+
+	// Computed, the defaults of the optional params of the constructor and
+	// the methods
+	Defaults []paramDefault `json:"-"`
+}
+
+// Default value of an optional param, declared as a constant, or as a
+// variable if its type can't be constant
+type paramDefault struct {
+	Name  string
+	Type  string
+	Value string
+	Const bool
+	Doc   string
 }
 
 type Constructor struct {
 	Name   string
 	Doc    string
 	Params []map[string]interface{}
+
+	// Computed, the required param creating the object, if any
+	Parent string `json:"-"`
 }
 
 type Method struct {
@@ -155,264 +595,1517 @@ type Method struct {
 	Return map[string]interface{}
 }
 
+type Event struct {
+	Name       string
+	Doc        string
+	Extends    string
+	Properties []map[string]interface{}
+}
+
 type ComplexType struct {
 	TypeFormat string
 	Doc        string
 	Values     []string
 	Name       string
+	Extends    string
 	Properties []map[string]interface{}
-}
 
-// template func that change Mediaxxx to IMediaxxx to be
-// sure to work with interface.
-// Set it global to be used by funcMap["paramValue"] above.
-func tplCheckElement(p string) string {
-	if len(p) > 5 && p[:5] == "Media" {
-		if p[len(p)-4:] != "Type" {
-			return "IMedia" + p[5:]
-		}
-	}
-	return p
+	// Computed for types that other types extend
+	Subtypes       []string          `json:"-"`
+	Discriminators map[string]string `json:"-"`
 }
 
-func isComplexType(t string) bool {
-	for _, c := range CPXTYPES {
-		if c == t {
+// Return true if the complex type "t" extends "parent", directly or not.
+func extendsComplexType(t, parent string) bool {
+	for p := CPXEXTENDS[t]; p != ""; p = CPXEXTENDS[p] {
+		if p == parent {
 			return true
 		}
 	}
 	return false
 }
 
-var funcMap = template.FuncMap{
-	"title":        strings.Title,
-	"uppercase":    strings.ToUpper,
-	"checkElement": tplCheckElement,
-	"paramValue": func(p map[string]interface{}) string {
-		name := p["name"].(string)
-		t := p["type"].(string)
-		t = tplCheckElement(t)
-
-		ctype := isComplexType(t)
-		switch t {
-		case "float64", "int":
-			return fmt.Sprintf("\"%s\" = %s", name, name)
-		case "string", "boolean":
-			return fmt.Sprintf("\"%s\" = %s", name, name)
-		default:
-			if !ctype && t[0] == 'I' {
-				return fmt.Sprintf("\"%s\" = fmt.Sprintf(\"%%s\", %s)", name, name)
-			}
-		}
-		return fmt.Sprintf("\"%s\" = %s", name, name)
-	},
-}
-
-func parseComplexTypes(complexs []string, suffix string) {
-	var paths []string
-
-	// save kmds file to paths
-	for _, path := range complexs {
-		pathList, err := filepath.Glob(path)
-		if err != nil {
-			logFatal(err)
+// Return every complex type extending "t", sorted by name.
+func complexSubtypes(t string) []string {
+	var ret []string
+	for name := range CPXEXTENDS {
+		if extendsComplexType(name, t) {
+			ret = append(ret, name)
 		}
-		paths = append(paths, pathList...)
 	}
+	sort.Strings(ret)
+	return ret
+}
 
-	// parse
+// Return the complex types extending "t" in its package. The ones of other
+// packages are decoded as "t", as its package can't import them.
+func packageSubtypes(t string) []string {
 	var ret []string
-	for _, path := range paths {
-
-		ctypes := getModel(path).ComplexTypes
-
-		if ctypes == nil {
-			continue
-		}
-
-		for _, ctype := range ctypes {
-			CPXTYPES = append(CPXTYPES, ctype.Name)
-
-			ctype.Doc = formatDoc(ctype.Doc)
-
-			for i, p := range ctype.Properties {
-				ctype.Properties[i] = formatTypes(p)
-			}
-
-			buff := bytes.NewBufferString("")
-			tpl, err := template.New("complexttypes").Funcs(funcMap).Parse(complexTypeTemplate)
-			if err != nil {
-
-			}
-
-			tpl.Execute(buff, ctype)
-			ret = append(ret, buff.String())
+	for _, sub := range complexSubtypes(t) {
+		if definitionPackage(sub) == definitionPackage(t) {
+			ret = append(ret, sub)
 		}
-
-		writeFile(createFile(path, suffix), ret)
 	}
+	return ret
 }
 
-func parseRemotes(remotes []string) {
-
-	var paths []string
-	// save kmds file to paths
-	for _, path := range remotes {
-		pathList, err := filepath.Glob(path)
-		if err != nil {
-			logFatal(err)
-		}
-		paths = append(paths, pathList...)
+// Return the "type" values selecting a subtype of "t".
+func complexDiscriminators(t string) map[string]string {
+	root := t
+	for CPXEXTENDS[root] != "" {
+		root = CPXEXTENDS[root]
 	}
 
-	for _, p := range paths {
-
-		c := getModel(p).RemoteClasses
-		ret := make([]string, len(c))
-
-		for idx, cl := range c {
-
-			fmt.Println("Generating ", cl.Name)
-
-			for j, p := range cl.Properties {
-				p = formatTypes(p)
-				switch p["type"] {
-				case "string", "float64", "bool", "[]string":
-				default:
-					if _, ok := p["type"].(string); ok {
-						if p["type"].(string)[:2] == "[]" {
-							t := p["type"].(string)[2:]
-							if isComplexType(t) {
-								p["type"] = "[]*" + t
-							} else {
-								p["type"] = "[]I" + t
-							}
-						} else {
-							if isComplexType(p["type"].(string)) {
-								p["type"] = "*" + p["type"].(string)
-							} else {
-								p["type"] = "I" + p["type"].(string)
-							}
-						}
-					}
-				}
-				cl.Properties[j] = p
-			}
-
-			for j, m := range cl.Methods {
-
-				for i, p := range m.Params {
-					p := formatTypes(p)
-					m.Params[i] = p
-				}
-
-				m.Doc = formatDoc(m.Doc)
-
-				if m.Return["type"] != nil {
-					m.Return = formatTypes(m.Return)
-					m.Return["doc"] = formatDoc(m.Return["doc"].(string))
-				}
-
-				cl.Methods[j] = m
-			}
-
-			for j, p := range cl.Constructor.Params {
-				p := formatTypes(p)
-				cl.Constructor.Params[j] = p
-			}
-
-			tpl, err := template.New("structure").Funcs(funcMap).Parse(strTemplate)
-			if err != nil {
-				logFatal(err)
-			}
-
-			buff := bytes.NewBufferString("")
-			cl.Doc = formatDoc(cl.Doc)
-
-			err = tpl.Execute(buff, cl)
-			if err != nil {
-				logFatal(err)
-			}
-
-			ret[idx] = buff.String()
+	ret := make(map[string]string)
+	for kind, name := range typeDiscriminators[root] {
+		if name == t || extendsComplexType(name, t) {
+			ret[kind] = name
 		}
-
-		writeFile(createFile(p, ""), ret)
 	}
+	return ret
 }
 
-func formatDoc(doc string) string {
-	doc = strings.Replace(doc, ":rom:cls:", "", -1)
-	doc = strings.Replace(doc, ":term:", "", -1)
-	doc = strings.Replace(doc, "``", `"`, -1)
-	doc = strings.Replace(doc, "/*", "", -1)
-	doc = strings.Replace(doc, "*/", "", -1)
-
-	lines := strings.Split(doc, "\n")
-
-	var part []string
-
-	for _, line := range lines {
-
-		part = append(part, line)
+// Set "polymorphic" and "shape" on a formatted return value which type is
+// extended by other complex types, and use the interface type instead.
+// Such values are decoded to the type the server sent, by the function
+// "polymorphic".
+func setPolymorphic(p map[string]interface{}) {
+	t := p["type"].(string)
+	shape := ""
+	switch {
+	case strings.HasPrefix(t, "[]"):
+		shape = "[]"
+	case strings.HasPrefix(t, "map[string]"):
+		shape = "map"
 	}
+	base := strings.TrimPrefix(strings.TrimPrefix(t, "[]"), "map[string]")
+	name := base[strings.LastIndex(base, ".")+1:]
 
-	for i, p := range part {
-		part[i] = "/*" + strings.TrimSpace(p) + "*/"
+	if len(packageSubtypes(name)) == 0 {
+		return
 	}
+	p["polymorphic"] = qualifier(name) + "DecodeI" + name
+	p["shape"] = shape
+	p["type"] = strings.TrimSuffix(t, base) + iface(base)
+}
 
-	return strings.Join(part, "\n")
+// Go types of the kmd primitive types
+var primitiveTypes = map[string]string{
+	"String":  "string",
+	"boolean": "bool",
+	"int":     "int",
+	"int64":   "int64",
+	"float":   "float32",
+	"double":  "float64",
 }
 
-func formatTypes(p map[string]interface{}) map[string]interface{} {
+// Where a kmd type is used. Remote classes are not mapped to the same Go
+// type everywhere.
+const (
+	// Returned values, properties, fields of events and complex types.
+	// Remote objects are decoded from their id into the class.
+	valueType = iota
 
-	p["doc"] = formatDoc(p["doc"].(string))
+	// Params of methods, setters and constructors. Any object implementing
+	// the class interface is accepted.
+	paramType
+)
 
-	if p["type"] == "String[]" {
-		p["type"] = "[]string"
+// goType returns the Go type of the kmd type "t", used as "usage".
+// "T[]" is an array of T and "T<>" a map of T with string keys.
+func goType(t string, usage int) (string, error) {
+	switch {
+	case strings.HasSuffix(t, "[]"):
+		elem, err := goType(strings.TrimSuffix(t, "[]"), usage)
+		return "[]" + elem, err
+	case strings.HasSuffix(t, "<>"):
+		elem, err := goType(strings.TrimSuffix(t, "<>"), usage)
+		return "map[string]" + elem, err
 	}
 
-	if p["type"] == "String" || p["type"] == "String<>" {
-		p["type"] = "string"
+	if gt, ok := primitiveTypes[t]; ok {
+		return gt, nil
 	}
-
-	if p["type"] == "float" || p["type"] == "double" {
-		p["type"] = "float64"
+	if isComplexType(t) {
+		return qualifier(t) + t, nil
+	}
+	if _, ok := CLASSES[t]; ok {
+		if usage == paramType {
+			return qualifier(t) + "I" + t, nil
+		}
+		return "*" + qualifier(t) + t, nil
 	}
+	return "", fmt.Errorf("unknown type %q", t)
+}
 
-	if p["type"] == "boolean" {
-		p["type"] = "bool"
+// checkTypes resolves every type used by the loaded kmd files. It returns
+// an error listing all the types that can't be resolved, the types
+// extending unknown ones, and the default values not matching their type.
+func checkTypes() error {
+	var errs []string
+	check := func(where string, p map[string]interface{}) {
+		t, _ := p["type"].(string)
+		if _, err := goType(t, valueType); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.%s: %s", where, p["name"], err))
+		} else if v := p["defaultValue"]; v != nil {
+			if _, _, err := goValue(t, v); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.%s: default value: %s", where, p["name"], err))
+			}
+		}
 	}
 
-	// type
-	if re.MatchString(p["type"].(string)) {
-		found := re.FindAllStringSubmatch(p["type"].(string), -1)
-		p["type"] = "[]" + found[0][1]
+	for name, cl := range CLASSES {
+		if _, ok := CLASSES[cl.Extends]; cl.Extends != "" && !ok {
+			errs = append(errs, fmt.Sprintf("%s: extends unknown class %q", name, cl.Extends))
+		}
+		for _, p := range cl.Properties {
+			check(name, p)
+		}
+		if cl.Constructor != nil {
+			for _, p := range cl.Constructor.Params {
+				check(name+".constructor", p)
+			}
+		}
+		for _, m := range cl.Methods {
+			for _, p := range m.Params {
+				check(name+"."+m.Name, p)
+			}
+			if m.Return["type"] != nil {
+				check(name+"."+m.Name, map[string]interface{}{
+					"name": "return",
+					"type": m.Return["type"],
+				})
+			}
+		}
+		for _, ev := range cl.Events {
+			if _, ok := EVENTS[ev]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: unknown event %q", name, ev))
+			}
+		}
 	}
 
-	// expect <>
-	if symbol.MatchString(p["type"].(string)) {
-		substr := p["type"].(string)
-		p["type"] = substr[0 : len(substr)-2]
+	for name, ctype := range CPXTYPEMODELS {
+		if ctype.Extends != "" && !isComplexType(ctype.Extends) {
+			errs = append(errs, fmt.Sprintf("%s: extends unknown type %q", name, ctype.Extends))
+		}
+		for _, p := range ctype.Properties {
+			check(name, p)
+		}
 	}
 
-	// default value
-	if p["defaultValue"] == "" || p["defaultValue"] == nil {
-		switch p["type"] {
-		case "string":
-			p["defaultValue"] = `""`
-		case "bool":
-			p["defaultValue"] = "false"
-		case "int", "float64":
-			p["defaultValue"] = "0"
+	for name, ev := range EVENTS {
+		if _, ok := EVENTS[ev.Extends]; ev.Extends != "" && !ok {
+			errs = append(errs, fmt.Sprintf("%s: extends unknown event %q", name, ev.Extends))
+		}
+		for _, p := range ev.Properties {
+			check(name+"Event", p)
 		}
 	}
 
-	return p
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("invalid kmd types:\n\t%s", strings.Join(errs, "\n\t"))
 }
 
-func getModel(path string) Core {
-	i := Core{}
+// goValue returns the Go expression of "v", the JSON default value of a
+// param of the kmd type "t", of the Go type of "t" as a value. It is
+// constant if "t" is a primitive type or an enum.
+func goValue(t string, v interface{}) (string, bool, error) {
+	switch {
+	case strings.HasSuffix(t, "[]"):
+		values, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		var elems []string
+		for _, e := range values {
+			elem, err := elemValue(strings.TrimSuffix(t, "[]"), e)
+			if err != nil {
+				return "", false, err
+			}
+			elems = append(elems, elem)
+		}
+		gt, err := goType(t, valueType)
+		return gt + "{" + strings.Join(elems, ", ") + "}", false, err
+	case strings.HasSuffix(t, "<>"):
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var elems []string
+		for key, e := range values {
+			elem, err := elemValue(strings.TrimSuffix(t, "<>"), e)
+			if err != nil {
+				return "", false, err
+			}
+			elems = append(elems, strconv.Quote(key)+": "+elem)
+		}
+		sort.Strings(elems)
+		gt, err := goType(t, valueType)
+		return gt + "{" + strings.Join(elems, ", ") + "}", false, err
+	case t == "String":
+		if s, ok := v.(string); ok {
+			return strconv.Quote(s), true, nil
+		}
+	case t == "boolean":
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b), true, nil
+		}
+	case t == "float" || t == "double":
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'g', -1, 64), true, nil
+		}
+	case t == "int" || t == "int64":
+		if f, ok := v.(float64); ok && f == math.Trunc(f) {
+			return strconv.FormatFloat(f, 'f', -1, 64), true, nil
+		}
+	case isComplexType(t):
+		return complexValue(t, v)
+	default:
+		return "", false, fmt.Errorf("%s can't have a default value", t)
+	}
+	j, _ := json.Marshal(v)
+	return "", false, fmt.Errorf("%s is not a %s", j, t)
+}
+
+// Return the Go expression of "v", an element of an array or a map of the
+// kmd type "t". The type of composite literals is elided.
+func elemValue(t string, v interface{}) (string, error) {
+	value, _, err := goValue(t, v)
+	if gt, _ := goType(t, valueType); strings.HasPrefix(value, gt+"{") {
+		value = strings.TrimPrefix(value, gt)
+	}
+	return value, err
+}
+
+// Return the Go expression of "v", the JSON value of the complex type "t".
+// Enum values are constants, and other complex types composite literals.
+func complexValue(t string, v interface{}) (string, bool, error) {
+	j, _ := json.Marshal(v)
+	if ctype := CPXTYPEMODELS[t]; ctype.TypeFormat == "ENUM" {
+		for _, value := range ctype.Values {
+			if value == v {
+				return qualifier(t) + strings.ToUpper(t) + "_" + strings.ToUpper(value), true, nil
+			}
+		}
+		return "", false, fmt.Errorf("%s is not a value of %s", j, t)
+	}
+
+	values, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false, fmt.Errorf("%s is not a %s", j, t)
+	}
+	// the fields are removed once set, from a copy
+	fields := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if !strings.HasPrefix(k, "__") {
+			fields[k] = v
+		}
+	}
+	ret, err := structValue(t, fields)
+	if len(fields) > 0 {
+		// the same field is reported on every run
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", false, fmt.Errorf("%s has no field %q", t, names[0])
+	}
+	return ret, false, err
+}
+
+// Return the composite literal of the complex type "t", setting the fields
+// of "fields" it and its parents declare, and removing them from fields.
+func structValue(t string, fields map[string]interface{}) (string, error) {
+	var elems []string
+	if parent := CPXEXTENDS[t]; parent != "" {
+		value, err := structValue(parent, fields)
+		if err != nil {
+			return "", err
+		}
+		elems = append(elems, parent+": "+value)
+	}
+	for _, p := range CPXTYPEMODELS[t].Properties {
+		name, _ := p["name"].(string)
+		v, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var value string
+		var err error
+		if p["optional"] == true && !nilable(p["type"].(string)) {
+			value, err = pointerValue(p["type"].(string), v)
+		} else {
+			value, _, err = goValue(p["type"].(string), v)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %s", t, name, err)
+		}
+		elems = append(elems, strings.Title(name)+": "+value)
+		delete(fields, name)
+	}
+	return qualifier(t) + t + "{" + strings.Join(elems, ", ") + "}", nil
+}
+
+// Functions of the runtime returning a pointer to their argument, by Go
+// type
+var pointerFuncs = map[string]string{
+	"bool":    "Bool",
+	"int":     "Int",
+	"int64":   "Int64",
+	"float32": "Float32",
+	"float64": "Float64",
+	"string":  "String",
+}
+
+// Return the Go expression of a pointer to "v", the JSON value of an
+// optional field of the kmd type "t" that can't be nil.
+func pointerValue(t string, v interface{}) (string, error) {
+	value, isConst, err := goValue(t, v)
+	if err != nil {
+		return "", err
+	}
+	if !isConst {
+		// composite literal
+		return "&" + value, nil
+	}
+	gt, err := goType(t, valueType)
+	if err != nil {
+		return "", err
+	}
+	if f, ok := pointerFuncs[gt]; ok {
+		return packageQualifier(*packageName) + f + "(" + value + ")", nil
+	}
+	// enum constant
+	return "func() *" + gt + " { v := " + value + "; return &v }()", nil
+}
+
+// Return true if the Go type of the kmd type "t" can be nil: arrays, maps
+// and remote classes.
+func nilable(t string) bool {
+	_, ok := CLASSES[t]
+	return ok || strings.HasSuffix(t, "[]") || strings.HasSuffix(t, "<>")
+}
+
+func isComplexType(t string) bool {
+	_, ok := CPXTYPEMODELS[t]
+	return ok
+}
+
+// Return the Go identifiers declared for the "kind" definition "name". The
+// first one is the type itself.
+func identifiers(kind, name string) []string {
+	switch kind {
+	case classDef:
+		return []string{name, "I" + name}
+	case eventDef:
+		return []string{name + "Event"}
+	}
+	return []string{name}
+}
+
+// define registers the "kind" definition "name" of the kmd file "path". It
+// returns false if the same definition is already registered by another
+// file, and an error if one of its identifiers is already declared by a
+// different definition.
+func define(kind, name, path string, model interface{}) (bool, error) {
+	idents := identifiers(kind, name)
+	for _, id := range idents {
+		prev, ok := DEFINITIONS[id]
+		if !ok {
+			continue
+		}
+		if prev.kind == kind && prev.name == name && reflect.DeepEqual(prev.model, model) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s: %s %s conflicts with %s %s of %s",
+			path, kind, name, prev.kind, prev.name, prev.path)
+	}
+
+	for _, id := range idents {
+		DEFINITIONS[id] = definition{kind, name, path, model}
+	}
+	return true, nil
+}
+
+// Return true if the "kind" definition "name" must be written in the file
+// generated for the kmd file "path".
+func definedIn(kind, name, path string) bool {
+	return DEFINITIONS[identifiers(kind, name)[0]].path == path
+}
+
+// Return the Go package of the kmd module "name". The modules are generated
+// in the package of core, unless -split is set.
+func packageOf(name string) string {
+	if !*split || name == "" || name == "core" {
+		return *packageName
+	}
+	ret := ""
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' && ret != "" {
+			ret += string(r)
+		}
+	}
+	return ret
+}
+
+// Return the directory of the package "pkg", relative to the output
+// directory, and its import path.
+func packageDir(pkg string) (string, string) {
+	dir := ""
+	switch {
+	case pkg == *packageName:
+	case pkg == "kurentofake":
+		dir = "kurentofake"
+	case strings.HasSuffix(pkg, "fake"):
+		base, _ := packageDir(strings.TrimSuffix(pkg, "fake"))
+		dir = filepath.Join(base, pkg)
+	default:
+		dir = pkg
+	}
+	return dir, strings.TrimSuffix(path.Join(*importPath, filepath.ToSlash(dir)), "/")
+}
+
+// Return the package of the fakes of the classes of the package "pkg".
+func fakePackage(pkg string) string {
+	if pkg == *packageName {
+		return "kurentofake"
+	}
+	return pkg + "fake"
+}
+
+// Return the qualifier of the identifiers of the package "pkg", used in
+// the package being generated.
+func packageQualifier(pkg string) string {
+	if PACKAGE == "" || pkg == PACKAGE {
+		return ""
+	}
+	return pkg + "."
+}
+
+// Return the package of the Go identifier "name", declared for a kmd
+// definition.
+func definitionPackage(name string) string {
+	return packageOf(MODULEOF[DEFINITIONS[name].path])
+}
+
+// Return the qualifier of the Go identifier "name", declared for a kmd
+// definition.
+func qualifier(name string) string {
+	return packageQualifier(definitionPackage(name))
+}
+
+// Import paths of the standard packages used by the templates, by name
+var stdImports = map[string]string{
+	"context": "context",
+	"json":    "encoding/json",
+	"sync":    "sync",
+}
+
+// Return the import paths of the packages the generated files may use, by
+// name, except the package being generated.
+func packageImports() map[string]string {
+	ret := make(map[string]string)
+	for name, importPath := range stdImports {
+		ret[name] = importPath
+	}
+	for name := range MODULES {
+		pkg := packageOf(name)
+		for _, p := range []string{pkg, fakePackage(pkg)} {
+			if p != PACKAGE {
+				_, ret[p] = packageDir(p)
+			}
+		}
+	}
+	return ret
+}
+
+// checkModules returns an error listing the modules importing modules that
+// are not loaded, or not in a version they require.
+func checkModules() error {
+	var errs []string
+	for name, m := range MODULES {
+		for _, imp := range m.Imports {
+			dep, ok := MODULES[imp.Name]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Sprintf("%s: imports module %s, which is not loaded", name, imp.Name))
+			case !versionMatches(dep.Version, imp.Version):
+				errs = append(errs, fmt.Sprintf("%s: imports %s %s, not %s", name, imp.Name, imp.Version, dep.Version))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("cannot resolve kmd modules:\n\t%s", strings.Join(errs, "\n\t"))
+}
+
+// Return true if the "version" of a module, as "6.7.1" or "6.7.2-dev",
+// matches the "required" versions, as "^6.7.0", "~6.7.0", ">=6.7.0 <7.0.0"
+// or "6.7.1". Unknown versions match.
+func versionMatches(version, required string) bool {
+	if version == "" || required == "" || required == "*" {
+		return true
+	}
+	v := parseVersion(version)
+	for _, r := range strings.Fields(required) {
+		i := strings.IndexAny(r, "0123456789")
+		if i < 0 {
+			continue
+		}
+		op := r[:i]
+		want := parseVersion(r[i:])
+		cmp := compareVersions(v, want)
+
+		ok := false
+		switch op {
+		case "^":
+			ok = cmp >= 0 && v[0] == want[0]
+		case "~":
+			ok = cmp >= 0 && v[0] == want[0] && v[1] == want[1]
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "", "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Return the major, minor and patch numbers of "version". Pre-releases, as
+// "-dev", are ignored.
+func parseVersion(version string) [3]int {
+	var ret [3]int
+	version = strings.SplitN(version, "-", 2)[0]
+	for i, n := range strings.SplitN(version, ".", 3) {
+		ret[i], _ = strconv.Atoi(n)
+	}
+	return ret
+}
+
+// Return -1, 0 or 1 if the version "a" is lower, equal or greater than "b".
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+var funcMap = template.FuncMap{
+	"title":     strings.Title,
+	"uppercase": strings.ToUpper,
+	"runtime":   func() string { return packageQualifier(*packageName) },
+	"qualify":   func(name string) string { return qualifier(name) + name },
+	"iface":     iface,
+	"fake":      fakeOf,
+	"event":     func(name string) string { return qualifier(name+"Event") + name + "Event" },
+	"fakes":     func() string { return fakePackage(PACKAGE) },
+}
+
+// Return the interface of the class "t", as "kurento.IMediaElement" for
+// "kurento.MediaElement".
+func iface(t string) string {
+	i := strings.LastIndex(t, ".") + 1
+	return t[:i] + "I" + t[i:]
+}
+
+// Return the fake of the class "t", as "kurentofake.MediaElement" for
+// "kurento.MediaElement".
+func fakeOf(t string) string {
+	i := strings.LastIndex(t, ".")
+	if i < 0 {
+		return t
+	}
+	return packageQualifier(fakePackage(t[:i])) + t[i+1:]
+}
+
+func parseComplexTypes(paths []string, suffix string) error {
+	tpl := template.Must(template.New("complexttypes").Funcs(funcMap).Parse(complexTypeTemplate))
+
+	for _, path := range paths {
+		PACKAGE = packageOf(MODULEOF[path])
+		var ret []string
+		for _, ctype := range getModel(path).ComplexTypes {
+			if !definedIn(complexTypeDef, ctype.Name, path) {
+				continue
+			}
+
+			ctype.Doc = formatDoc(ctype.Doc)
+			if ctype.Extends != "" {
+				ctype.Extends = qualifier(ctype.Extends) + ctype.Extends
+			}
+			ctype.Subtypes = packageSubtypes(ctype.Name)
+			if len(ctype.Subtypes) > 0 {
+				ctype.Discriminators = complexDiscriminators(ctype.Name)
+			}
+
+			for i, p := range ctype.Properties {
+				ctype.Properties[i] = formatTypes(p, valueType)
+				// unset optional fields are not sent, the ones that can't be
+				// nil are pointers
+				if p["optional"] == true {
+					ctype.Properties[i]["omitEmpty"] = true
+					if !nilable(p["type"].(string)) {
+						ctype.Properties[i]["type"] = "*" + ctype.Properties[i]["type"].(string)
+					}
+				}
+			}
+
+			buff := bytes.NewBufferString("")
+			if err := tpl.Execute(buff, ctype); err != nil {
+				return fmt.Errorf("complex type %s: %s", ctype.Name, err)
+			}
+			ret = append(ret, buff.String())
+		}
+
+		if len(ret) > 0 {
+			if err := writeFile(createFile(path, suffix), "", ret); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// eventProperties returns properties of the event, including the ones
+// inherited from its parents. A property redefined by a child replaces
+// the parent one.
+func eventProperties(ev Event) []map[string]interface{} {
+	var props []map[string]interface{}
+	if parent, ok := EVENTS[ev.Extends]; ok && ev.Extends != ev.Name {
+		props = eventProperties(parent)
+	}
+
+	for _, p := range ev.Properties {
+		replaced := false
+		for i, prev := range props {
+			if prev["name"] == p["name"] {
+				props[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+func parseEvents(paths []string, suffix string) error {
+	tpl := template.Must(template.New("events").Funcs(funcMap).Parse(eventTemplate))
+
+	for _, path := range paths {
+		PACKAGE = packageOf(MODULEOF[path])
+		var ret []string
+		for _, ev := range getModel(path).Events {
+			if !definedIn(eventDef, ev.Name, path) {
+				continue
+			}
+
+			ev.Doc = formatDoc(ev.Doc)
+			ev.Properties = eventProperties(ev)
+			for i, p := range ev.Properties {
+				ev.Properties[i] = formatTypes(p, valueType)
+			}
+
+			buff := bytes.NewBufferString("")
+			if err := tpl.Execute(buff, ev); err != nil {
+				return fmt.Errorf("event %s: %s", ev.Name, err)
+			}
+			ret = append(ret, buff.String())
+		}
+
+		if len(ret) > 0 {
+			if err := writeFile(createFile(path, suffix), "", ret); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Return the properties of the class, and of the classes it extends, that
+// can only be set when the object is created, and are not constructor
+// params. They are set by the options of the constructor, and sent apart
+// from the constructor params. "declared" is false if the classes don't
+// declare any final property, constructor params included.
+func finalProperties(cl Class) (ret []map[string]interface{}, declared bool) {
+	params := make(map[string]bool)
+	if cl.Constructor != nil {
+		for _, p := range cl.Constructor.Params {
+			params[p["name"].(string)] = true
+		}
+	}
+	for c, ok := cl, true; ok; c, ok = CLASSES[c.Extends] {
+		for _, p := range c.Properties {
+			if p["final"] != true || p["readOnly"] == true {
+				continue
+			}
+			declared = true
+			if !params[p["name"].(string)] {
+				ret = append(ret, p)
+			}
+		}
+		if c.Extends == c.Name {
+			break
+		}
+	}
+	return ret, declared
+}
+
+func parseRemotes(paths []string) error {
+
+	for _, p := range paths {
+		pkg := packageOf(MODULEOF[p])
+		PACKAGE = pkg
+
+		var ret, fakes []string
+		for _, cl := range getModel(p).RemoteClasses {
+			if !definedIn(classDef, cl.Name, p) {
+				continue
+			}
+
+			if *verbose {
+				fmt.Println("Generating", cl.Name)
+			}
+
+			code, err := generateClass(cl)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, code)
+
+			fake, err := generateFake(cl)
+			if err != nil {
+				return err
+			}
+			fakes = append(fakes, fake)
+		}
+
+		if len(ret) > 0 {
+			if err := writeFile(createFile(p, ""), "", ret); err != nil {
+				return err
+			}
+			PACKAGE = fakePackage(pkg)
+			if err := writeFile(createFile(p, ""), "", fakes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateClass returns the code of the remote class "cl". Its types must
+// have been registered by loadModels.
+func generateClass(cl Class) (string, error) {
+	return executeClass("structure", strTemplate, formatClass(cl))
+}
+
+// generateFake returns the code of the fake of the remote class "cl", in
+// the package of the fakes of the package being generated.
+func generateFake(cl Class) (string, error) {
+	pkg := PACKAGE
+	defer func() { PACKAGE = pkg }()
+	PACKAGE = fakePackage(pkg)
+	return executeClass("fake", fakeTemplate, formatClass(cl))
+}
+
+// Execute the template "text" on the formatted class "cl". Errors name the
+// class, and the method, property, event or constructor the template fails
+// on.
+func executeClass(name, text string, cl Class) (string, error) {
+	tpl, err := template.New(name).Funcs(funcMap).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	execute := func(cl Class) (string, error) {
+		buff := bytes.NewBufferString("")
+		err := tpl.Execute(buff, cl)
+		return buff.String(), err
+	}
+
+	ret, err := execute(cl)
+	if err == nil {
+		return ret, nil
+	}
+
+	// execute the template on each member alone, to find the failing one
+	bare := cl
+	bare.Constructor, bare.Methods, bare.Properties, bare.Events = nil, nil, nil, nil
+	if _, err := execute(bare); err != nil {
+		return "", fmt.Errorf("class %s: %s", cl.Name, err)
+	}
+	if cl.Constructor != nil {
+		c := bare
+		c.Constructor = cl.Constructor
+		if _, err := execute(c); err != nil {
+			return "", fmt.Errorf("class %s, constructor: %s", cl.Name, err)
+		}
+	}
+	for _, m := range cl.Methods {
+		c := bare
+		c.Methods = []Method{m}
+		if _, err := execute(c); err != nil {
+			return "", fmt.Errorf("class %s, method %s: %s", cl.Name, m.Name, err)
+		}
+	}
+	for _, p := range cl.Properties {
+		c := bare
+		c.Properties = []map[string]interface{}{p}
+		if _, err := execute(c); err != nil {
+			return "", fmt.Errorf("class %s, property %s: %s", cl.Name, p["name"], err)
+		}
+	}
+	for _, ev := range cl.Events {
+		c := bare
+		c.Events = []string{ev}
+		if _, err := execute(c); err != nil {
+			return "", fmt.Errorf("class %s, event %s: %s", cl.Name, ev, err)
+		}
+	}
+	return "", fmt.Errorf("class %s: %s", cl.Name, err)
+}
+
+// formatClass returns a copy of "cl" with formatted docs and Go types.
+func formatClass(cl Class) Class {
+	// the classes created by the runtime package return their own final
+	// properties, as the ones they inherit may be their constructor params
+	final, declared := finalProperties(cl)
+	cl.FinalProperties = nil
+	cl.FinalMethod = declared && cl.Constructor != nil
+	for _, p := range final {
+		cl.FinalProperties = append(cl.FinalProperties, p["name"].(string))
+	}
+
+	props := make([]map[string]interface{}, len(cl.Properties))
+	for j, p := range cl.Properties {
+		props[j] = formatTypes(p, valueType)
+		props[j]["setType"] = formatTypes(p, paramType)["type"]
+
+		// getters are called and decoded as methods without params
+		ret := map[string]interface{}{"type": props[j]["type"]}
+		setPolymorphic(ret)
+		props[j]["type"] = ret["type"]
+		props[j]["getter"] = Method{
+			Constructor: Constructor{Name: "get" + strings.Title(p["name"].(string))},
+			Return:      ret,
+		}
+	}
+	cl.Properties = props
+
+	cl.Defaults = nil
+	if cl.Constructor != nil {
+		c := *cl.Constructor
+		c.Params = make([]map[string]interface{}, len(cl.Constructor.Params))
+		for j, p := range cl.Constructor.Params {
+			if _, ok := CLASSES[p["type"].(string)]; ok && p["optional"] != true && c.Parent == "" {
+				c.Parent = p["name"].(string)
+			}
+			// remote objects are given by their interface
+			c.Params[j] = formatTypes(p, paramType)
+			if d, ok := declareDefault(p, c.Params[j], cl.Name, "New"+cl.Name); ok {
+				cl.Defaults = append(cl.Defaults, d)
+			}
+		}
+		// final properties are set like optional params
+		for _, p := range final {
+			param := formatTypes(p, paramType)
+			param["optional"] = true
+			c.Params = append(c.Params, param)
+		}
+		c.Doc = formatDoc(c.Doc + "\n\n" + paramsDoc(c.Params, cl.Name))
+		cl.Constructor = &c
+	}
+
+	methods := make([]Method, len(cl.Methods))
+	for j, m := range cl.Methods {
+		params := make([]map[string]interface{}, len(m.Params))
+		for i, p := range m.Params {
+			params[i] = formatTypes(p, paramType)
+			// optional params are not sent when nil
+			if p["optional"] == true && !nilable(p["type"].(string)) {
+				params[i]["type"] = "*" + params[i]["type"].(string)
+			}
+			name := strings.Title(m.Name)
+			if d, ok := declareDefault(p, params[i], cl.Name+name, name); ok {
+				cl.Defaults = append(cl.Defaults, d)
+			}
+		}
+		m.Params = params
+
+		doc := m.Doc + "\n\n" + paramsDoc(m.Params, "")
+		if r, _ := m.Return["doc"].(string); r != "" {
+			doc += "\n\nReturns: " + r
+		}
+		m.Doc = formatDoc(doc)
+
+		if m.Return["type"] != nil {
+			m.Return = formatTypes(m.Return, valueType)
+			setPolymorphic(m.Return)
+		}
+
+		methods[j] = m
+	}
+	cl.Methods = methods
+
+	if cl.Extends != "" {
+		cl.Extends = qualifier(cl.Extends) + cl.Extends
+	}
+	cl.Doc = formatDoc(cl.Doc)
+	return cl
+}
+
+// Kinds of the blocks of a doc comment
+const (
+	docParagraph = iota
+	docHeading
+	docItem
+	docCode
+)
+
+// A paragraph, heading, list item or code block of a doc comment. Number
+// is the number of the items of ordered lists, 0 for the other blocks.
+type docBlock struct {
+	kind   int
+	number int
+	text   string
+}
+
+var (
+	// "@deprecated", and the reason given on the same line, if any
+	deprecatedDoc = regexp.MustCompile(`(?i)@deprecated\b[ \t]*([^\n<]*)`)
+	// Reason given in parentheses at the start of a deprecated doc
+	deprecatedReason = regexp.MustCompile(`^(?:<br\s*/?>|</br>|\s)*\(([^)]*)\)\s*`)
+	// Sphinx role, as :rom:cls:`MediaElement` or :term:`ICE`, and the
+	// plural "s" following it, if any
+	docRole = regexp.MustCompile(":(?:rom:)?(\\w+):`([^`]+)`(s\\b)?")
+	// Target of a role with an explicit title, as "MediaElements<MediaElement>"
+	docRoleTarget = regexp.MustCompile(`^(.*?)\s*<([^<>]+)>$`)
+	// Inline literal, as ``value`` or <code>value</code>
+	docLiteral = regexp.MustCompile("(?is)``(.+?)``|<code>(.*?)</code>")
+	docAnchor  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	docStrong  = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	// HTML tags used by kmd docs. Other text between "<" and ">" is kept,
+	// as "<encoding name>"
+	docTag = regexp.MustCompile(`(?i)</?(p|br|hr|div|ul|ol|li|h[1-6]|pre|b|strong|i|em|u|tt|span|code)\b[^>]*>`)
+	// Item of a reStructuredText list
+	docListItem = regexp.MustCompile(`^\s*(?:[-*+]|(\d+)[.)])\s+`)
+)
+
+// formatDoc converts the kmd doc "doc", written in HTML and
+// reStructuredText, into a Go doc comment wrapped at DOCLINELENGTH columns.
+// References to kmd definitions become doc links, and deprecated
+// definitions end with a "Deprecated:" paragraph.
+func formatDoc(doc string) string {
+	var lines []string
+	prev := -1
+	for _, b := range docBlocks(doc) {
+		if prev >= 0 && (b.kind != docItem || prev != docItem) {
+			lines = append(lines, "//")
+		}
+		prev = b.kind
+
+		switch b.kind {
+		case docHeading:
+			lines = append(lines, "// # "+b.text)
+		case docCode:
+			for _, line := range strings.Split(b.text, "\n") {
+				lines = append(lines, strings.TrimRight("//\t"+line, " \t"))
+			}
+		case docItem:
+			marker := "  - "
+			if b.number > 0 {
+				marker = fmt.Sprintf("%2d. ", b.number)
+			}
+			lines = append(lines, wrapComment(b.text, "// "+marker, "//     ")...)
+		default:
+			lines = append(lines, wrapComment(b.text, "// ", "// ")...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Return the blocks of the kmd doc "doc", with their inline markup
+// converted.
+func docBlocks(doc string) []docBlock {
+	// the reason is given after @deprecated, or in parentheses at the
+	// start of the doc
+	if m := deprecatedDoc.FindStringSubmatchIndex(doc); m != nil {
+		reason := strings.TrimSpace(doc[m[2]:m[3]])
+		doc = doc[:m[0]] + doc[m[1]:]
+		if m := deprecatedReason.FindStringSubmatch(doc); m != nil && reason == "" {
+			reason = strings.TrimSpace(m[1])
+			doc = doc[len(m[0]):]
+		}
+		switch {
+		case reason == "":
+			reason = "the media server module deprecates it."
+		case !strings.HasSuffix(reason, "."):
+			reason += "."
+		}
+		doc += "<p>Deprecated: " + capitalize(reason) + "</p>"
+	}
+
+	doc = docAnchor.ReplaceAllStringFunc(doc, func(s string) string {
+		m := docAnchor.FindStringSubmatch(s)
+		if m[2] == m[1] {
+			return m[1]
+		}
+		return m[2] + " (" + m[1] + ")"
+	})
+	doc = docLiteral.ReplaceAllStringFunc(doc, func(s string) string {
+		m := docLiteral.FindStringSubmatch(s)
+		text := strings.TrimSpace(docTag.ReplaceAllString(m[1]+m[2], ""))
+		if link := docLink(text); link != "" {
+			return link
+		}
+		return `"` + text + `"`
+	})
+	doc = docRole.ReplaceAllStringFunc(doc, func(s string) string {
+		m := docRole.FindStringSubmatch(s)
+		return docReference(m[1], m[2], m[3])
+	})
+	doc = docStrong.ReplaceAllString(doc, "$1")
+
+	p := &docParser{}
+	p.parse(doc)
+	if len(p.blocks) > 0 {
+		p.blocks[0].text = capitalize(p.blocks[0].text)
+	}
+	return p.blocks
+}
+
+// Return the text of the Sphinx role "role" referencing "ref", followed by
+// "suffix". References to kmd definitions become doc links.
+func docReference(role, ref, suffix string) string {
+	text, target := ref, ref
+	if m := docRoleTarget.FindStringSubmatch(ref); m != nil {
+		text, target = m[1], m[2]
+	}
+	// "~" only shortens the title to the last name
+	target = strings.TrimPrefix(target, "~")
+	if strings.HasPrefix(text, "~") {
+		text = target
+	}
+	text += suffix
+
+	link := ""
+	if role != "term" {
+		link = docLink(target)
+	}
+	switch {
+	case link == "":
+		return text
+	case text == target:
+		return link
+	case text == target+"s":
+		return link + " objects"
+	}
+	return text + " (" + link + ")"
+}
+
+// Return the doc link to the Go identifier declared for "ref", a kmd
+// definition as "MediaElement", or one of its members as
+// "MediaElement.connect" or "MediaType.AUDIO". It returns "" if there is
+// no such identifier.
+func docLink(ref string) string {
+	name, member := ref, ""
+	if i := strings.Index(ref, "."); i >= 0 {
+		name, member = ref[:i], ref[i+1:]
+	}
+	def, ok := DEFINITIONS[name]
+	if !ok {
+		// events are referenced with or without their suffix
+		def, ok = DEFINITIONS[name+"Event"]
+	}
+	if !ok {
+		return ""
+	}
+
+	if member == "" {
+		id := identifiers(def.kind, def.name)[0]
+		return "[" + qualifier(id) + id + "]"
+	}
+	if id := memberIdentifier(def, member); id != "" {
+		return "[" + id + "]"
+	}
+	return ""
+}
+
+// Return the qualified Go identifier of the member "name" of the definition
+// "def": the method of a class, or the method getting its property, the
+// constant of an enum value, or the field of a complex type or event.
+// Members are looked for in the parents of the definition too, and named
+// after the type declaring them.
+func memberIdentifier(def definition, name string) string {
+	switch def.kind {
+	case classDef:
+		for cl, ok := CLASSES[def.name]; ok; cl, ok = CLASSES[cl.Extends] {
+			for _, m := range cl.Methods {
+				if m.Name == name {
+					return qualifier(cl.Name) + cl.Name + "." + strings.Title(name)
+				}
+			}
+			for _, p := range cl.Properties {
+				if p["name"] == name {
+					return qualifier(cl.Name) + cl.Name + ".Get" + strings.Title(name)
+				}
+			}
+			if cl.Extends == cl.Name {
+				break
+			}
+		}
+	case complexTypeDef:
+		for t := def.name; t != ""; t = CPXEXTENDS[t] {
+			ctype := CPXTYPEMODELS[t]
+			for _, v := range ctype.Values {
+				if v == name {
+					return qualifier(t) + strings.ToUpper(t) + "_" + strings.ToUpper(v)
+				}
+			}
+			for _, p := range ctype.Properties {
+				if p["name"] == name {
+					return qualifier(t) + t + "." + strings.Title(name)
+				}
+			}
+		}
+	case eventDef:
+		for _, p := range eventProperties(EVENTS[def.name]) {
+			if p["name"] == name {
+				return qualifier(def.name+"Event") + def.name + "Event." + strings.Title(name)
+			}
+		}
+	}
+	return ""
+}
+
+// Splits kmd docs into blocks, from their HTML tags and their
+// reStructuredText paragraphs and lists.
+type docParser struct {
+	blocks []docBlock
+	// Block being read, and its text
+	block docBlock
+	text  []string
+	// Set in bold text starting a block, as <b>Note</b>
+	label bool
+	// Number of the last item of the lists being read, -1 for unordered
+	// lists
+	lists []int
+	// Set in <pre>
+	pre bool
+}
+
+// Parse the doc "doc", with its inline markup converted.
+func (p *docParser) parse(doc string) {
+	for {
+		loc := docTag.FindStringSubmatchIndex(doc)
+		if loc == nil {
+			p.parseText(doc)
+			break
+		}
+		p.parseText(doc[:loc[0]])
+		p.parseTag(strings.ToLower(doc[loc[2]:loc[3]]), doc[loc[0]+1] == '/')
+		doc = doc[loc[1]:]
+	}
+	p.end(docParagraph)
+}
+
+// Parse the opening or closing HTML "tag".
+func (p *docParser) parseTag(tag string, closing bool) {
+	switch tag {
+	case "p", "br", "hr", "div":
+		// as in <li><p>
+		if p.block.kind == docItem && p.empty() {
+			break
+		}
+		p.end(docParagraph)
+	case "pre":
+		p.end(docParagraph)
+		if !closing {
+			p.block.kind = docCode
+		}
+		p.pre = !closing
+	case "ul", "ol":
+		p.end(docParagraph)
+		switch {
+		case closing && len(p.lists) > 0:
+			p.lists = p.lists[:len(p.lists)-1]
+		case !closing && tag == "ul":
+			p.lists = append(p.lists, -1)
+		case !closing:
+			p.lists = append(p.lists, 0)
+		}
+	case "li":
+		p.end(docParagraph)
+		if !closing {
+			p.item(0)
+		}
+	case "b", "strong":
+		// bold labels end with a colon, as "Note:"
+		if !closing {
+			p.label = p.empty()
+		} else if text := strings.TrimSpace(strings.Join(p.text, "")); p.label && !strings.HasSuffix(text, ":") {
+			p.text = append(p.text, ":")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		p.end(docParagraph)
+		if !closing {
+			p.block.kind = docHeading
+		}
+	}
+}
+
+// Start a list item, numbered "number" if it is not 0 or if it is in an
+// ordered HTML list.
+func (p *docParser) item(number int) {
+	p.block.kind = docItem
+	if n := len(p.lists); n > 0 && number == 0 && p.lists[n-1] >= 0 {
+		p.lists[n-1]++
+		number = p.lists[n-1]
+	}
+	p.block.number = number
+}
+
+// Parse the text between two tags. Blank lines end paragraphs, and lines
+// starting as list items start one.
+func (p *docParser) parseText(text string) {
+	text = html.UnescapeString(text)
+	if p.pre {
+		p.text = append(p.text, text)
+		return
+	}
+	for i, line := range strings.Split(text, "\n") {
+		switch {
+		case i > 0 && strings.TrimSpace(line) == "":
+			p.end(docParagraph)
+		case (i > 0 || p.empty()) && docListItem.MatchString(line):
+			m := docListItem.FindStringSubmatch(line)
+			p.end(docParagraph)
+			number, _ := strconv.Atoi(m[1])
+			p.item(number)
+			p.text = append(p.text, line[len(m[0]):])
+		case i > 0:
+			p.text = append(p.text, " "+line)
+		default:
+			p.text = append(p.text, line)
+		}
+	}
+}
+
+// Return true if the block being read has no text yet.
+func (p *docParser) empty() bool {
+	return strings.TrimSpace(strings.Join(p.text, "")) == ""
+}
+
+// End the block being read, if it has text, and start a block of "kind".
+func (p *docParser) end(kind int) {
+	text := strings.Join(p.text, "")
+	if p.block.kind == docCode {
+		text = strings.Trim(text, "\n")
+	} else {
+		text = strings.Join(strings.Fields(text), " ")
+	}
+	if text != "" {
+		p.block.text = text
+		p.blocks = append(p.blocks, p.block)
+	}
+	p.block = docBlock{kind: kind}
+	p.text = nil
+}
+
+// Return "text" with its first letter in upper case.
+func capitalize(text string) string {
+	for i, r := range text {
+		return text[:i] + strings.ToUpper(string(r)) + text[i+len(string(r)):]
+	}
+	return text
+}
+
+// formatTypes returns a copy of the kmd param, property or return value
+// "p" with a formatted doc and its Go type, used as "usage". Types must
+// have been checked by checkTypes.
+func formatTypes(p map[string]interface{}, usage int) map[string]interface{} {
+	ret := make(map[string]interface{}, len(p))
+	for k, v := range p {
+		ret[k] = v
+	}
+	p = ret
+
+	doc, _ := p["doc"].(string)
+	p["doc"] = formatDoc(doc)
+	p["rawDoc"] = doc
+
+	t, err := goType(p["type"].(string), usage)
+	if err != nil {
+		logFatal(err)
+	}
+	p["type"] = t
+	return p
+}
+
+// Return the declaration of the default value of the param "p" of the
+// function "fn", named prefix+"Default"+param, and false if it is not an
+// optional param with a default value. The param formatted by formatTypes,
+// "formatted", names it as "default". Values must have been checked by
+// checkTypes.
+func declareDefault(p, formatted map[string]interface{}, prefix, fn string) (paramDefault, bool) {
+	v := p["defaultValue"]
+	if v == nil || p["optional"] != true {
+		return paramDefault{}, false
+	}
+	t := p["type"].(string)
+	value, isConst, err := goValue(t, v)
+	if err != nil {
+		logFatal(err)
+	}
+	gt, _ := goType(t, valueType)
+
+	name := prefix + "Default" + strings.Title(p["name"].(string))
+	formatted["default"] = name
+	return paramDefault{
+		Name:  name,
+		Type:  gt,
+		Value: value,
+		Const: isConst,
+		Doc: formatComment(fmt.Sprintf(
+			"%s is the default of the %s param of %s, used by the media server when it is not set.",
+			name, p["name"], fn)),
+	}, true
+}
+
+// Return the kmd doc of the params "params", telling the required ones from
+// the optional ones, and their default. Optional params set by options are
+// named after their option "prefix"+param.
+func paramsDoc(params []map[string]interface{}, prefix string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	ret := "<p>Params:</p><ul>"
+	optional := false
+	for _, p := range params {
+		name := p["name"].(string)
+		optional = optional || p["optional"] == true
+		ret += "<li>"
+		switch {
+		case p["optional"] != true:
+			ret += name + ": "
+		case prefix != "":
+			ret += "[" + prefix + strings.Title(name) + "] (optional): "
+		default:
+			ret += name + " (optional): "
+		}
+		doc := strings.TrimSpace(p["rawDoc"].(string))
+		ret += doc
+		if d, ok := p["default"]; ok {
+			if doc != "" && !strings.HasSuffix(doc, ".") {
+				ret += "."
+			}
+			ret += " Defaults to [" + d.(string) + "]."
+		}
+		ret += "</li>"
+	}
+	ret += "</ul>"
+	if prefix == "" && optional {
+		ret += "<p>Optional params are not sent when nil.</p>"
+	}
+	return ret
+}
+
+// Return kmd files matching the "globs".
+func kmdFiles(globs []string) []string {
+	var paths []string
+	for _, path := range globs {
+		pathList, err := filepath.Glob(path)
+		if err != nil {
+			logFatal(err)
+		}
+		paths = append(paths, pathList...)
+	}
+	return paths
+}
+
+// loadModels registers every class, complex type and event defined in the
+// kmd files. Types can be used by files other than the one defining them.
+// A definition repeated identically by several files belongs to the first
+// one. It returns an error listing all the name collisions.
+func loadModels(paths []string) error {
+	var errs []string
+	for _, path := range paths {
+		model := getModel(path)
+		name := moduleOf(path)
+		m := MODULES[name]
+		if m == nil {
+			m = &module{Name: name}
+			MODULES[name] = m
+		}
+		m.paths = append(m.paths, path)
+		MODULEOF[path] = name
+		if model.Name != "" {
+			m.Version = model.Version
+			m.Imports = model.Imports
+		}
+
+		for _, cl := range model.RemoteClasses {
+			ok, err := define(classDef, cl.Name, path, cl)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			if ok {
+				CLASSES[cl.Name] = cl
+			}
+		}
+		for _, ctype := range model.ComplexTypes {
+			ok, err := define(complexTypeDef, ctype.Name, path, ctype)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			if !ok {
+				continue
+			}
+			CPXTYPEMODELS[ctype.Name] = ctype
+			if ctype.Extends != "" {
+				CPXEXTENDS[ctype.Name] = ctype.Extends
+			}
+		}
+		for _, ev := range model.Events {
+			ok, err := define(eventDef, ev.Name, path, ev)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			if ok {
+				EVENTS[ev.Name] = ev
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("conflicting kmd definitions:\n\t%s", strings.Join(errs, "\n\t"))
+}
+
+func getModel(path string) Core {
+	i := Core{}
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		logFatal(err)
@@ -426,68 +2119,722 @@ func getModel(path string) Core {
 	return i
 }
 
-func writeFile(path string, classess []string) {
+// Return the name of the module of the kmd file "path". The files of the
+// classes of a module, as "elements.WebRtcEndpoint.kmd.json", don't name
+// it.
+func moduleOf(path string) string {
+	if name := getModel(path).Name; name != "" {
+		return name
+	}
+	return strings.SplitN(filepath.Base(path), ".", 2)[0]
+}
+
+// Return the kmd files of the "modules", or all of them if empty.
+func selectModules(paths, modules []string) []string {
+	if len(modules) == 0 {
+		return paths
+	}
+	var ret []string
+	for _, path := range paths {
+		for _, m := range modules {
+			if moduleOf(path) == m {
+				ret = append(ret, path)
+			}
+		}
+	}
+	return ret
+}
+
+// Return an error if one of the "modules" imports a module that is not
+// selected, unless -split is set. The modules of a single package are
+// generated together, and can't use the types of the other ones.
+func checkSelection(modules []string) error {
+	if *split || len(modules) == 0 {
+		return nil
+	}
+	selected := make(map[string]bool)
+	for _, m := range modules {
+		selected[m] = true
+	}
+	for _, m := range modules {
+		for _, imp := range MODULES[m].Imports {
+			if !selected[imp.Name] {
+				return fmt.Errorf("module %s imports %s; add it to -modules or use -split", m, imp.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Return true if the runtime is generated with the "modules", or all of
+// them if empty. With -split, it is in the package of core, and is not
+// generated again with the other modules.
+func runtimeSelected(modules []string) bool {
+	if !*split || len(modules) == 0 {
+		return true
+	}
+	for _, m := range modules {
+		if m == "core" {
+			return true
+		}
+	}
+	return false
+}
+
+// Write the "classess" to "path", in the package being generated, with
+// the documentation "doc".
+func writeFile(path, doc string, classess []string) error {
 	content := strings.Join(classess, "\n")
-	tpl, _ := template.New("package").Parse(packageTemplate)
+	tpl := template.Must(template.New("package").Parse(packageTemplate))
 	buff := bytes.NewBufferString("")
-	tpl.Execute(buff, map[string]string{
+	err := tpl.Execute(buff, map[string]interface{}{
+		"Doc":     doc,
+		"Package": PACKAGE,
 		"Content": content,
 	})
-	ioutil.WriteFile(path, buff.Bytes(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	src, err := addImports(path, buff.Bytes(), packageImports())
+	if err != nil {
+		return err
+	}
+	GENERATED[path] = src
+	return nil
+}
+
+// Add to the file "filename" the imports of the packages "src" uses, found
+// by name in "imports". Packages already imported are not added again.
+func addImports(filename string, src []byte, imports map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go code: %s", err)
+	}
+
+	imported := make(map[string]bool)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imported[name] = true
+	}
+	// package names are not resolved by the parser
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil && !imported[x.Name] && imports[x.Name] != "" {
+			used[x.Name] = true
+		}
+		return true
+	})
+	if len(used) == 0 {
+		return src, nil
+	}
+
+	// standard packages first, as goimports does
+	var std, others []string
+	for name := range used {
+		spec := strconv.Quote(imports[name])
+		if path.Base(imports[name]) != name {
+			spec = name + " " + spec
+		}
+		if stdImports[name] == imports[name] {
+			std = append(std, spec)
+		} else {
+			others = append(others, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	specs := std
+	if len(std) > 0 && len(others) > 0 {
+		specs = append(specs, "")
+	}
+	specs = append(specs, others...)
+
+	decl := "import " + specs[0]
+	if len(specs) > 1 {
+		decl = "import (\n" + strings.Join(specs, "\n") + "\n)"
+	}
+	i := fset.Position(file.Name.End()).Offset
+	ret := append([]byte(nil), src[:i]...)
+	ret = append(ret, "\n\n"+decl+"\n"...)
+	return append(ret, src[i:]...), nil
 }
 
+// Return the path of the file generated for the kmd file "path", in the
+// directory of the package being generated. The path is relative to the
+// output directory.
 func createFile(path string, suffix string) string {
 	base := filepath.Base(path)
 	base = strings.Replace(base, ".kmd.json", "", -1)
 	base = strings.Replace(base, ".", "_", -1)
-	base = "kurento/" + base
 	if suffix != "" {
 		base += "_" + suffix + ".go"
 	} else {
 		base += ".go"
 	}
-	return strings.ToLower(base)
+	dir, _ := packageDir(PACKAGE)
+	return filepath.Join(dir, strings.ToLower(base))
+}
+
+// Add a doc.go file to each package generated for the kmd files "paths",
+// naming the modules and versions it is generated from.
+func writeDocs(paths []string) error {
+	versions := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		m := MODULES[MODULEOF[path]]
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+		desc := m.Name
+		if m.Version != "" {
+			desc += " " + m.Version
+		}
+		pkg := packageOf(m.Name)
+		versions[pkg] = append(versions[pkg], desc)
+	}
+
+	for pkg, descs := range versions {
+		list := strings.Join(descs, ", ")
+		if i := strings.LastIndex(list, ", "); i >= 0 {
+			list = list[:i] + " and " + list[i+2:]
+		}
+		kind := "module"
+		if len(descs) > 1 {
+			kind = "modules"
+		}
+
+		PACKAGE = pkg
+		err := writeFile(createFile("doc", ""), formatComment(fmt.Sprintf(
+			"Package %s is a client of the Kurento Media Server, generated from the kmd files of the %s %s.",
+			pkg, kind, list)), nil)
+		if err != nil {
+			return err
+		}
+		// the fakes of the root package are documented by the runtime
+		if pkg != *packageName {
+			PACKAGE = fakePackage(pkg)
+			err := writeFile(createFile("doc", ""), formatComment(fmt.Sprintf(
+				"Package %s provides fakes of the classes of the %s package. See the kurentofake package.",
+				PACKAGE, pkg)), nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Return "text" as a line comment, wrapped at DOCLINELENGTH columns.
+func formatComment(text string) string {
+	return strings.Join(wrapComment(text, "// ", "// "), "\n")
+}
+
+// Return the lines of "text" wrapped at DOCLINELENGTH columns, starting with
+// "first" for the first line and "rest" for the others. Words longer than
+// a line are not split.
+func wrapComment(text, first, rest string) []string {
+	var lines []string
+	line := first
+	for i, word := range strings.Fields(text) {
+		switch {
+		case i == 0:
+			line += word
+		case len(line)+1+len(word) > DOCLINELENGTH:
+			lines = append(lines, line)
+			line = rest + word
+		default:
+			line += " " + word
+		}
+	}
+	return append(lines, strings.TrimRight(line, " "))
 }
 
 func logFatal(err error) {
 	log.Fatal(err)
 }
 
-func main() {
+// GENERATED holds the generated files by path, relative to the output
+// directory, until they are written or checked.
+var GENERATED = make(map[string][]byte)
+
+// Package and import path the runtime sources are written for. They are
+// replaced by the ones of the generated package.
+const (
+	runtimePackage    = "package kurento\n"
+	runtimeImportPath = `"kurento-client-go-generator/kurento`
+)
+
+// Add the runtime files found in "dir", with their subpackages, to the
+// generated files.
+func copyRuntime(dir string) error {
+	return filepath.Walk(dir, func(f string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// the tests of the runtime use the classes of testdata/kmd, and
+		// are run by the tests of the generator
+		if info.IsDir() || filepath.Ext(f) != ".go" || strings.HasSuffix(f, "_test.go") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, f)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		src := string(data)
+		src = strings.Replace(src, runtimePackage, "package "+*packageName+"\n", 1)
+		// the subpackages refer to the runtime as "kurento"
+		alias := ""
+		if *packageName != "kurento" {
+			alias = "kurento "
+		}
+		src = strings.Replace(src, runtimeImportPath+`"`, alias+`"`+*importPath+`"`, -1)
+		src = strings.Replace(src, runtimeImportPath+"/", `"`+*importPath+"/", -1)
+		GENERATED[rel] = []byte(src)
+		return nil
+	})
+}
+
+// Format the generated files with gofmt. Their imports are added by
+// writeFile.
+func formatGenerated() error {
+	for path, src := range GENERATED {
+		out, err := format.Source(src)
+		if err != nil {
+			return fmt.Errorf("%s:%s", path, err)
+		}
+		GENERATED[path] = wrapComments(out)
+	}
+	return nil
+}
+
+// wrapComments wraps the comment lines of "src" longer than DOCLINELENGTH,
+// as the ones of the templates, written on one line whatever the length of
+// the names they use. The code blocks of the docs are kept as they are.
+func wrapComments(src []byte) []byte {
+	var ret []string
+	for _, line := range strings.Split(string(src), "\n") {
+		text := strings.TrimLeft(line, "\t")
+		if len(line) <= DOCLINELENGTH || !strings.HasPrefix(text, "// ") {
+			ret = append(ret, line)
+			continue
+		}
+		indent := line[:len(line)-len(text)]
+		ret = append(ret, wrapComment(text[len("// "):], indent+"// ", indent+"// ")...)
+	}
+	return []byte(strings.Join(ret, "\n"))
+}
+
+// typeCheckGenerated parses and type-checks the packages of the generated
+// files. The packages they import that are not generated are read from
+// "dir". Uses of the packages that can't be imported, as golang.org/x/net
+// when it is not installed, are not checked.
+func typeCheckGenerated(dir string) error {
+	imp := &generatedImporter{
+		dir:      dir,
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*types.Package),
+		failed:   make(map[string]error),
+		exports:  make(map[string]string),
+		missing:  make(map[string]bool),
+	}
+	imp.std = importer.ForCompiler(imp.fset, "gc", imp.exportData)
+
+	dirs := make(map[string]bool)
+	for path := range GENERATED {
+		dirs[filepath.ToSlash(filepath.Dir(path))] = true
+	}
+	var errs []string
+	for d := range dirs {
+		if _, err := imp.Import(path.Join(*importPath, d)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for importPath := range imp.missing {
+		log.Printf("%s can't be imported, its uses are not type-checked", importPath)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("generated invalid Go code:\n%s", strings.Join(errs, "\n"))
+}
+
+// Open the export data of the package "importPath", built by the go tool.
+// The export data of its dependencies is found at the same time.
+func (imp *generatedImporter) exportData(importPath string) (io.ReadCloser, error) {
+	if _, ok := imp.exports[importPath]; !ok {
+		out, err := exec.Command("go", "list", "-export", "-deps", "-f", "{{ .ImportPath }} {{ .Export }}", importPath).Output()
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if i := strings.LastIndex(line, " "); i > 0 {
+				imp.exports[line[:i]] = line[i+1:]
+			}
+		}
+	}
+	export := imp.exports[importPath]
+	if export == "" {
+		return nil, fmt.Errorf("no export data for %s", importPath)
+	}
+	return os.Open(export)
+}
+
+// Imports the generated packages, and the other ones with "std".
+type generatedImporter struct {
+	dir      string
+	fset     *token.FileSet
+	std      types.Importer
+	packages map[string]*types.Package
+	failed   map[string]error
+
+	// Export data of the other packages, by import path
+	exports map[string]string
+
+	// Packages that can't be imported by "std"
+	missing map[string]bool
+}
+
+func (imp *generatedImporter) Import(pkgPath string) (*types.Package, error) {
+	if pkg, ok := imp.packages[pkgPath]; ok {
+		return pkg, nil
+	}
+	if err, ok := imp.failed[pkgPath]; ok {
+		return nil, err
+	}
+	rel := "."
+	switch {
+	case pkgPath == *importPath:
+	case strings.HasPrefix(pkgPath, *importPath+"/"):
+		rel = strings.TrimPrefix(pkgPath, *importPath+"/")
+	default:
+		pkg, err := imp.std.Import(pkgPath)
+		if err != nil {
+			imp.missing[pkgPath] = true
+		}
+		return pkg, err
+	}
 
-	// Write base data to dst
-	data, err := ioutil.ReadFile("kurento_go_base/base.go")
+	files, err := imp.parse(rel)
 	if err != nil {
-		logFatal(err)
+		return nil, err
+	}
+	var errs []string
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			// the uses of missing packages are not checked
+			if e, ok := err.(types.Error); !ok || !strings.Contains(e.Msg, "could not import") {
+				errs = append(errs, "\t"+err.Error())
+			}
+		},
 	}
-	err = ioutil.WriteFile("kurento/base.go", data, os.ModePerm)
+	pkg, _ := conf.Check(pkgPath, imp.fset, files, nil)
+	if len(errs) > 0 {
+		imp.failed[pkgPath] = fmt.Errorf("%s\n%s", pkgPath, strings.Join(errs, "\n"))
+		return nil, imp.failed[pkgPath]
+	}
+	imp.packages[pkgPath] = pkg
+	return pkg, nil
+}
+
+// Parse the Go files of the package in the directory "rel" of the output,
+// as they will be once the generated files are written: the generated
+// files, or the files of the directory if none is generated in it. Tests
+// are ignored.
+func (imp *generatedImporter) parse(rel string) ([]*ast.File, error) {
+	sources := make(map[string][]byte)
+	for path, src := range GENERATED {
+		if filepath.ToSlash(filepath.Dir(path)) == rel {
+			sources[path] = src
+		}
+	}
+	if len(sources) == 0 {
+		existing, _ := filepath.Glob(filepath.Join(imp.dir, rel, "*.go"))
+		for _, f := range existing {
+			data, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			sources[filepath.Join(rel, filepath.Base(f))] = data
+		}
+	}
+
+	var files []*ast.File
+	for path, src := range sources {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(imp.fset, path, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// rename renames files for writeGenerated. Tests replace it to make it fail.
+var rename = os.Rename
+
+// Write the generated files to "dir", and remove the obsolete ones. Every
+// file is written to a temporary file first. Then the files replaced or
+// removed are moved aside, and restored if one of the renames fails, so
+// that "dir" is left as it was.
+func writeGenerated(dir string) error {
+	tmps := make(map[string]string)
+	defer func() {
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}()
+
+	var dsts []string
+	for path, src := range GENERATED {
+		dst := filepath.Join(dir, path)
+		dsts = append(dsts, dst)
+		if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+			return err
+		}
+		f, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+".")
+		if err != nil {
+			return err
+		}
+		tmps[dst] = f.Name()
+		_, err = f.Write(src)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(f.Name(), 0644); err != nil {
+			return err
+		}
+	}
+	sort.Strings(dsts)
+	obsolete, err := obsoleteGenerated(dir)
 	if err != nil {
-		logFatal(err)
+		return err
+	}
+
+	backups := make(map[string]string)
+	var written []string
+	restore := func() {
+		for _, dst := range written {
+			os.Remove(dst)
+		}
+		for dst, backup := range backups {
+			os.Rename(backup, dst)
+		}
+	}
+	moveAside := func(dst string) error {
+		if _, err := os.Lstat(dst); os.IsNotExist(err) {
+			return nil
+		}
+		backup := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".old")
+		if err := rename(dst, backup); err != nil {
+			return err
+		}
+		backups[dst] = backup
+		return nil
+	}
+
+	for _, dst := range dsts {
+		if err := moveAside(dst); err != nil {
+			restore()
+			return err
+		}
+		if err := rename(tmps[dst], dst); err != nil {
+			restore()
+			return err
+		}
+		delete(tmps, dst)
+		written = append(written, dst)
+	}
+	for _, path := range obsolete {
+		if err := moveAside(filepath.Join(dir, path)); err != nil {
+			restore()
+			return err
+		}
 	}
+	for _, backup := range backups {
+		os.Remove(backup)
+	}
+	return nil
+}
 
-	// write ws data to dst
-	data, err = ioutil.ReadFile("kurento_go_base/kurento_ws.go")
+// Return the generated files that are missing from "dir", or different,
+// and the obsolete files.
+func staleGenerated(dir string) []string {
+	var ret []string
+	for path, src := range GENERATED {
+		data, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil || !bytes.Equal(data, src) {
+			ret = append(ret, path)
+		}
+	}
+	obsolete, err := obsoleteGenerated(dir)
 	if err != nil {
 		logFatal(err)
 	}
-	err = ioutil.WriteFile("kurento/kurento_ws.go", data, os.ModePerm)
-	if err != nil {
+	for _, path := range obsolete {
+		ret = append(ret, path+" (obsolete)")
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Return the Go files of "dir" that are not generated any more, in the
+// directories of the generated files, relative to "dir". The generator
+// owns every Go file of these directories.
+func obsoleteGenerated(dir string) ([]string, error) {
+	dirs := make(map[string]bool)
+	for path := range GENERATED {
+		dirs[filepath.Dir(path)] = true
+	}
+	var ret []string
+	for d := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, d, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			path := filepath.Join(d, filepath.Base(f))
+			if _, ok := GENERATED[path]; !ok {
+				ret = append(ret, path)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// Values of a flag that can be repeated, or separated by commas
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, strings.Split(v, ",")...)
+	return nil
+}
+
+// Command line flags
+var (
+	kmdGlobs    listFlag
+	modules     listFlag
+	outDir      = flag.String("out", "kurento", "directory of the generated package")
+	packageName = flag.String("package", "kurento", "name of the generated package")
+	importPath  = flag.String("import", "kurento-client-go-generator/kurento", "import path of the generated package")
+	baseDir     = flag.String("base", "kurento_go_base", "directory of the runtime sources copied to the package")
+	check       = flag.Bool("check", false, "don't write the package, exit with status 1 if it is out of date")
+	split       = flag.Bool("split", false, "generate each kmd module but core in its own package, in a directory of the output named after it")
+	verbose     = flag.Bool("v", false, "print the name of each class generated")
+)
+
+// kmd files of the git submodules, read when no -kmd flag is given
+var defaultKmdGlobs = []string{
+	"kms-core/src/server/interface/core.kmd.json",
+	"kms-elements/src/server/interface/elements.kmd.json",
+	"kms-elements/src/server/interface/elements.*.kmd.json",
+	"kms-filters/src/server/interface/filters.kmd.json",
+	"kms-filters/src/server/interface/filters.*.kmd.json",
+}
+
+func init() {
+	flag.Var(&kmdGlobs, "kmd", "kmd files to generate, as globs (repeatable, default: the kms-* submodules)")
+	flag.Var(&modules, "modules", "names of the kmd modules to generate, as core,elements (default: all)")
+}
+
+// generate adds the files generated from the kmd files matching "globs" to
+// GENERATED, for the "modules", or all of them if empty.
+func generate(globs, modules []string) error {
+	// every module is loaded to resolve the types used by the selected ones
+	paths := kmdFiles(globs)
+	if len(paths) == 0 {
+		return fmt.Errorf("no kmd file matches %s", strings.Join(globs, ","))
+	}
+	if err := loadModels(paths); err != nil {
+		return err
+	}
+	if err := checkModules(); err != nil {
+		return err
+	}
+	if err := checkTypes(); err != nil {
+		return err
+	}
+	for _, m := range modules {
+		if MODULES[m] == nil {
+			return fmt.Errorf("module %s not found in %s", m, strings.Join(globs, ","))
+		}
+	}
+	if err := checkSelection(modules); err != nil {
+		return err
+	}
+	paths = selectModules(paths, modules)
+
+	if err := parseComplexTypes(paths, "complext_types"); err != nil {
+		return err
+	}
+	if err := parseEvents(paths, "events"); err != nil {
+		return err
+	}
+	if err := parseRemotes(paths); err != nil {
+		return err
+	}
+	return writeDocs(paths)
+}
+
+func main() {
+	flag.Parse()
+	if len(kmdGlobs) == 0 {
+		kmdGlobs = defaultKmdGlobs
+	}
+
+	if runtimeSelected(modules) {
+		if err := copyRuntime(*baseDir); err != nil {
+			logFatal(err)
+		}
+	}
+
+	if err := generate(kmdGlobs, modules); err != nil {
 		logFatal(err)
 	}
 
-	// ComplexTypes list
-	complexList := []string{
-		"kms-core/src/server/interface/core.kmd.json",
-		"kms-elements/src/server/interface/elements.*.kmd.json",
-		"kms-filters/src/server/interface/filters.*.kmd.json",
+	if err := formatGenerated(); err != nil {
+		logFatal(err)
+	}
+	// nothing is written if the package doesn't compile
+	if err := typeCheckGenerated(*outDir); err != nil {
+		logFatal(err)
 	}
-	parseComplexTypes(complexList, "complext_types")
 
-	// RemoteClasses list
-	remoteList := []string{
-		"kms-core/src/server/interface/core.kmd.json",
-		"kms-elements/src/server/interface/elements.*.kmd.json",
-		"kms-filters/src/server/interface/filters.*.kmd.json",
+	if *check {
+		stale := staleGenerated(*outDir)
+		if len(stale) > 0 {
+			log.Fatalf("%s is out of date, generate it again:\n\t%s", *outDir, strings.Join(stale, "\n\t"))
+		}
+		return
+	}
+	if err := writeGenerated(*outDir); err != nil {
+		logFatal(err)
 	}
-	parseRemotes(remoteList)
 }
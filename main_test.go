@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// Classes generated by the test, loaded like kmd definitions
+var testClasses = []Class{
+	{
+		Name: "MediaObject",
+	},
+	{
+		Name:        "MediaPipeline",
+		Extends:     "MediaObject",
+		Constructor: &Constructor{},
+	},
+	{
+		Name:    "WebRtcEndpoint",
+		Extends: "MediaObject",
+		Constructor: &Constructor{
+			Params: []map[string]interface{}{
+				{"name": "mediaPipeline", "type": "MediaPipeline"},
+				{"name": "useDataChannels", "type": "boolean", "optional": true},
+			},
+		},
+		Methods: []Method{
+			{
+				Constructor: Constructor{
+					Name:   "gatherCandidates",
+					Params: []map[string]interface{}{},
+				},
+			},
+		},
+	},
+}
+
+// Return the signature of a function type, without the names of params.
+func signature(f *ast.FuncType) string {
+	fields := func(l *ast.FieldList) string {
+		if l == nil {
+			return ""
+		}
+		var ret []string
+		for _, f := range l.List {
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				ret = append(ret, types.ExprString(f.Type))
+			}
+		}
+		return strings.Join(ret, ", ")
+	}
+	return "(" + fields(f.Params) + ") (" + fields(f.Results) + ")"
+}
+
+// Add the methods declared in "file" to "methods", by receiver type.
+func collectMethods(file *ast.File, methods map[string]map[string]string) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil {
+			continue
+		}
+		recv := strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*")
+		if methods[recv] == nil {
+			methods[recv] = make(map[string]string)
+		}
+		methods[recv][fn.Name.Name] = signature(fn.Type)
+	}
+}
+
+// Return the methods required by the interface "name" of "file", if any.
+func interfaceMethods(file *ast.File, name string) map[string]string {
+	var ret map[string]string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return true
+		}
+		ret = make(map[string]string)
+		for _, m := range spec.Type.(*ast.InterfaceType).Methods.List {
+			if f, ok := m.Type.(*ast.FuncType); ok {
+				ret[m.Names[0].Name] = signature(f)
+			}
+		}
+		return false
+	})
+	return ret
+}
+
+// TestMediaObjectContract fails when the templates don't define the methods
+// that the runtime requires from generated classes.
+func TestMediaObjectContract(t *testing.T) {
+	resetGenerator()
+	for _, cl := range testClasses {
+		CLASSES[cl.Name] = cl
+	}
+
+	fset := token.NewFileSet()
+	methods := make(map[string]map[string]string)
+	var contract map[string]string
+
+	files, err := filepath.Glob("kurento_go_base/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		collectMethods(file, methods)
+		if m := interfaceMethods(file, "mediaObject"); m != nil {
+			contract = m
+		}
+	}
+	if contract == nil {
+		t.Fatal("mediaObject is not declared by the runtime")
+	}
+
+	for _, cl := range testClasses {
+		code, err := generateClass(cl)
+		if err != nil {
+			t.Fatalf("%s: %s", cl.Name, err)
+		}
+		buff := bytes.NewBufferString("")
+		tpl := template.Must(template.New("package").Parse(packageTemplate))
+		if err := tpl.Execute(buff, map[string]string{"Doc": "", "Package": "kurento", "Content": code}); err != nil {
+			t.Fatal(err)
+		}
+		file, err := parser.ParseFile(fset, cl.Name+".go", buff, 0)
+		if err != nil {
+			t.Fatalf("%s: %s", cl.Name, err)
+		}
+		collectMethods(file, methods)
+
+		// ignore the alignment of the assertions
+		words := strings.Join(strings.Fields(code), " ")
+		for _, assertion := range []string{
+			"_ I" + cl.Name + " = (*" + cl.Name + ")(nil)",
+			"_ mediaObject = (*" + cl.Name + ")(nil)",
+		} {
+			if !strings.Contains(words, assertion) {
+				t.Errorf("%s: missing %q", cl.Name, assertion)
+			}
+		}
+	}
+
+	// Every class embeds MediaObject, and may override its methods
+	for _, cl := range testClasses {
+		for name, sig := range contract {
+			got, ok := methods[cl.Name][name]
+			if !ok {
+				got, ok = methods["MediaObject"][name]
+			}
+			switch {
+			case !ok:
+				t.Errorf("%s: mediaObject.%s is not implemented", cl.Name, name)
+			case got != sig:
+				t.Errorf("%s: %s%s doesn't match mediaObject.%s%s", cl.Name, name, got, name, sig)
+			}
+		}
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		version, required string
+		want              bool
+	}{
+		{"6.7.1", "^6.7.0", true},
+		{"6.7.2-dev", "^6.7.0", true},
+		{"7.0.0", "^6.7.0", false},
+		{"6.6.0", "^6.7.0", false},
+		{"6.7.9", "~6.7.0", true},
+		{"6.8.0", "~6.7.0", false},
+		{"6.9.0", ">=6.7.0 <7.0.0", true},
+		{"7.0.0", ">=6.7.0 <7.0.0", false},
+		{"6.7.1", "6.7.1", true},
+		{"6.7.1", "=6.7.0", false},
+		{"6.7.1", "*", true},
+		{"", "^6.7.0", true},
+	}
+	for _, test := range tests {
+		if got := versionMatches(test.version, test.required); got != test.want {
+			t.Errorf("versionMatches(%q, %q) = %v, want %v", test.version, test.required, got, test.want)
+		}
+	}
+}
+
+func TestFormatDoc(t *testing.T) {
+	resetGenerator()
+	tests := []struct {
+		doc, want string
+	}{
+		{"", ""},
+		{"Uses :term:`ICE` and :rom:cls:`Unknown`.", "// Uses ICE and Unknown."},
+		{"Steps:\n\n1. Connect\n2. Play", "// Steps:\n//\n//  1. Connect\n//  2. Play"},
+		{"<p>Example:</p><pre>\nelem.Play()\n\nelem.Stop()\n</pre>", "// Example:\n//\n//\telem.Play()\n//\n//\telem.Stop()"},
+		{"Media<b>Element</b> &amp; ``a&lt;b``", `// MediaElement & "a<b"`},
+		{"@deprecated\nold.", "// Old.\n//\n// Deprecated: The media server module deprecates it."},
+		{strings.Repeat("Word ", 20), "// " + strings.TrimSpace(strings.Repeat("Word ", 15)) + "\n// " + strings.TrimSpace(strings.Repeat("Word ", 5))},
+	}
+	for _, test := range tests {
+		if got := formatDoc(test.doc); got != test.want {
+			t.Errorf("formatDoc(%q) =\n%s\nwant\n%s", test.doc, got, test.want)
+		}
+	}
+}
+
+func TestGoValue(t *testing.T) {
+	resetGenerator()
+	CPXTYPEMODELS["Mode"] = ComplexType{TypeFormat: "ENUM", Name: "Mode", Values: []string{"SOLID", "NONE"}}
+	CPXTYPEMODELS["Base"] = ComplexType{Name: "Base", Properties: []map[string]interface{}{
+		{"name": "id", "type": "String"},
+	}}
+	CPXTYPEMODELS["Derived"] = ComplexType{Name: "Derived", Extends: "Base", Properties: []map[string]interface{}{
+		{"name": "modes", "type": "Mode[]"},
+	}}
+	CPXEXTENDS["Derived"] = "Base"
+	CPXTYPEMODELS["Optional"] = ComplexType{Name: "Optional", Properties: []map[string]interface{}{
+		{"name": "mode", "type": "Mode", "optional": true},
+		{"name": "count", "type": "int", "optional": true},
+		{"name": "base", "type": "Base", "optional": true},
+		{"name": "modes", "type": "Mode[]", "optional": true},
+	}}
+	CLASSES["Hub"] = Class{Name: "Hub"}
+
+	tests := []struct {
+		t     string
+		v     interface{}
+		want  string
+		isVar bool
+		err   bool
+	}{
+		{t: "int64", v: -1.0, want: "-1"},
+		{t: "int", v: 1.5, err: true},
+		{t: "float", v: 0.25, want: "0.25"},
+		{t: "String", v: `a"b`, want: `"a\"b"`},
+		{t: "boolean", v: "true", err: true},
+		{t: "Mode", v: "SOLID", want: "MODE_SOLID"},
+		{t: "Mode", v: "DOTTED", err: true},
+		{t: "int<>", v: map[string]interface{}{"b": 2.0, "a": 1.0}, want: `map[string]int{"a": 1, "b": 2}`, isVar: true},
+		{t: "Derived", v: map[string]interface{}{"id": "x", "modes": []interface{}{"NONE"}, "__type__": "Derived"},
+			want: `Derived{Base: Base{Id: "x"}, Modes: []Mode{MODE_NONE}}`, isVar: true},
+		{t: "Derived", v: map[string]interface{}{"unknown": 1.0}, err: true},
+		{t: "Optional", v: map[string]interface{}{"mode": "NONE", "count": 2.0, "base": map[string]interface{}{"id": "x"}, "modes": []interface{}{}},
+			want: `Optional{Mode: func() *Mode { v := MODE_NONE; return &v }(), Count: Int(2), Base: &Base{Id: "x"}, Modes: []Mode{}}`, isVar: true},
+		{t: "Hub", v: "hub", err: true},
+	}
+	for _, test := range tests {
+		got, isConst, err := goValue(test.t, test.v)
+		if (err != nil) != test.err || got != test.want || err == nil && isConst == test.isVar {
+			t.Errorf("goValue(%q, %#v) = %s, %v, %v", test.t, test.v, got, isConst, err)
+		}
+	}
+
+	// the unknown fields are reported in order, the same on every run
+	for i := 0; i < 10; i++ {
+		_, _, err := goValue("Base", map[string]interface{}{"c": 1.0, "b": 1.0, "a": 1.0})
+		if want := `Base has no field "a"`; err == nil || err.Error() != want {
+			t.Fatalf("got %v, want %s", err, want)
+		}
+	}
+}
+
+// TestExecuteClassError checks that template errors name the class and the
+// member the template fails on.
+func TestExecuteClassError(t *testing.T) {
+	resetGenerator()
+	cl := Class{
+		Name: "PlayerEndpoint",
+		Methods: []Method{
+			{Constructor: Constructor{Name: "play"}},
+			{Constructor: Constructor{Name: "seekTo"}},
+		},
+	}
+	text := `{{ range .Methods }}{{ if eq .Name "seekTo" }}{{ index .Name 10 }}{{ end }}{{ end }}`
+
+	_, err := executeClass("test", text, cl)
+	want := "class PlayerEndpoint, method seekTo: "
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got %v, want an error starting with %q", err, want)
+	}
+}
+
+// TestRepeatedDefinitions checks that definitions repeated by the kmd files
+// of a module are generated once, and that different definitions of the
+// same name are reported.
+func TestRepeatedDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	core, err := ioutil.ReadFile("testdata/kmd/core.kmd.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType := `{"typeFormat": "ENUM", "doc": "Type of media stream.", "values": ["AUDIO", "DATA", "VIDEO"], "name": "MediaType"}`
+	files := map[string]string{
+		"core.kmd.json":       string(core),
+		"elements.A.kmd.json": `{"complexTypes": [` + mediaType + `, {"typeFormat": "ENUM", "doc": "A.", "values": ["A"], "name": "A"}]}`,
+		"elements.B.kmd.json": `{"complexTypes": [` + mediaType + `]}`,
+		"elements.kmd.json":   `{"name": "elements", "version": "6.7.1", "imports": [{"name": "core", "version": "^6.7.0"}]}`,
+	}
+	write := func(files map[string]string) {
+		for name, src := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write(files)
+
+	resetGenerator()
+	if err := generate([]string{filepath.Join(dir, "*.kmd.json")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var declared []string
+	for path, src := range GENERATED {
+		if bytes.Contains(src, []byte("type MediaType string")) {
+			declared = append(declared, path)
+		}
+	}
+	if len(declared) != 1 || declared[0] != "core_complext_types.go" {
+		t.Errorf("MediaType declared in %v, want core_complext_types.go", declared)
+	}
+
+	write(map[string]string{
+		"elements.C.kmd.json": `{"complexTypes": [{"typeFormat": "ENUM", "doc": "Type.", "values": ["AUDIO"], "name": "MediaType"}]}`,
+	})
+	resetGenerator()
+	err = generate([]string{filepath.Join(dir, "*.kmd.json")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "elements.C.kmd.json") || !strings.Contains(err.Error(), "MediaType") {
+		t.Errorf("got %v, want the conflict of elements.C.kmd.json", err)
+	}
+}